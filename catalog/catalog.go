@@ -0,0 +1,74 @@
+// Package catalog holds the standard exercises a new deployment is seeded
+// with.
+package catalog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yeboahd24/workout-tracker/model"
+)
+
+//go:embed exercises.json
+var exercisesJSON []byte
+
+// Catalog is the embedded exercise dataset. Version is bumped whenever the
+// exercises change.
+type Catalog struct {
+	Version   int     `json:"version"`
+	Exercises []Entry `json:"exercises"`
+}
+
+// Entry describes one catalog exercise. Slug identifies it across catalog
+// versions and must never change once released; everything else may be
+// corrected in a later version.
+type Entry struct {
+	Slug             string   `json:"slug"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	Category         string   `json:"category"`
+	MeasurementType  string   `json:"measurement_type"`
+	PrimaryMuscles   []string `json:"primary_muscles"`
+	SecondaryMuscles []string `json:"secondary_muscles"`
+	Equipment        []string `json:"equipment"`
+	MovementPattern  string   `json:"movement_pattern"`
+	Laterality       string   `json:"laterality"`
+}
+
+// Load parses the embedded catalog, failing on duplicate slugs.
+func Load() (*Catalog, error) {
+	var c Catalog
+	if err := json.Unmarshal(exercisesJSON, &c); err != nil {
+		return nil, fmt.Errorf("parsing exercise catalog: %w", err)
+	}
+
+	seen := make(map[string]bool, len(c.Exercises))
+	for _, e := range c.Exercises {
+		if e.Slug == "" {
+			return nil, fmt.Errorf("catalog exercise %q has no slug", e.Name)
+		}
+		if seen[e.Slug] {
+			return nil, fmt.Errorf("duplicate catalog slug %q", e.Slug)
+		}
+		seen[e.Slug] = true
+	}
+	return &c, nil
+}
+
+// Exercise builds the global exercise the entry describes.
+func (e Entry) Exercise() (*model.Exercise, error) {
+	measurement, err := model.ParseMeasurementType(e.MeasurementType)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", e.Slug, err)
+	}
+
+	exercise := model.NewExercise(e.Name, e.Description, e.Category)
+	slug := e.Slug
+	exercise.Slug = &slug
+	exercise.MeasurementType = measurement
+	if err := exercise.SetTaxonomy(e.PrimaryMuscles, e.SecondaryMuscles, e.Equipment, e.MovementPattern, e.Laterality); err != nil {
+		return nil, fmt.Errorf("%s: %w", e.Slug, err)
+	}
+	return exercise, nil
+}
@@ -1,13 +1,23 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"time"
+	_ "time/tzdata"
 
+	"github.com/yeboahd24/workout-tracker/catalog"
 	"github.com/yeboahd24/workout-tracker/config"
+	"github.com/yeboahd24/workout-tracker/importer"
+	"github.com/yeboahd24/workout-tracker/repository"
 	"github.com/yeboahd24/workout-tracker/router"
+	"github.com/yeboahd24/workout-tracker/service"
 
 	_ "github.com/lib/pq"
 )
@@ -24,6 +34,38 @@ func main() {
 	}
 	defer db.Close()
 
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(db, os.Args[2:]); err != nil {
+			log.Fatalf("Error importing workouts: %v", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "seed-catalog" {
+		if err := runSeedCatalog(db, os.Args[2:]); err != nil {
+			log.Fatalf("Error seeding exercise catalog: %v", err)
+		}
+		return
+	}
+
+	// Generate workouts for recurring schedules as their horizon moves on
+	scheduleService := service.NewScheduleService(
+		repository.NewScheduleRepository(db),
+		repository.NewWorkoutRepository(db),
+		repository.NewTemplateRepository(db),
+	)
+	go scheduleService.Run(context.Background(), time.Hour)
+
+	// Delete accounts whose deletion grace period is over
+	accountService := service.NewAccountService(
+		repository.NewUserRepository(db),
+		repository.NewSessionRepository(db),
+		repository.NewExerciseRepository(db),
+		repository.NewWorkoutRepository(db),
+		repository.NewRecordRepository(db),
+	)
+	go accountService.RunPurge(context.Background(), time.Hour)
+
 	// Initialize router
 	r := router.SetupRouter(db, cfg)
 
@@ -32,6 +74,88 @@ func main() {
 	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", cfg.ServerPort), r))
 }
 
+// runImport loads a Strong or Hevy CSV export for a user from the command
+// line and prints the import result:
+//
+//	workout-tracker import -user johndoe -format strong [-tz Europe/London] [-dry-run] export.csv
+func runImport(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	username := fs.String("user", "", "username to import the workouts for")
+	formatName := fs.String("format", "", "export format: strong or hevy")
+	tz := fs.String("tz", "UTC", "time zone the export's times are in")
+	dryRun := fs.Bool("dry-run", false, "show what would be imported without saving it")
+	fs.Parse(args)
+
+	if *username == "" || fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("a -user and one file are required")
+	}
+
+	format, err := importer.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ctx := context.Background()
+	user, err := repository.NewUserRepository(db).GetByUsername(ctx, *username)
+	if err != nil {
+		return fmt.Errorf("looking up user %q: %w", *username, err)
+	}
+
+	importService := service.NewImportService(
+		repository.NewExerciseRepository(db),
+		repository.NewWorkoutRepository(db),
+		repository.NewRecordRepository(db),
+	)
+	result, err := importService.Import(ctx, user.ID, file, service.ImportOptions{
+		Format:   format,
+		Location: loc,
+		DryRun:   *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+// runSeedCatalog upserts the built-in exercise catalog into the global
+// catalog and prints what changed:
+//
+//	workout-tracker seed-catalog [-dry-run]
+func runSeedCatalog(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("seed-catalog", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "show what would change without saving it")
+	fs.Parse(args)
+
+	c, err := catalog.Load()
+	if err != nil {
+		return err
+	}
+
+	catalogService := service.NewCatalogService(repository.NewExerciseRepository(db))
+	result, err := catalogService.Seed(context.Background(), c, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
 func init() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 }
@@ -0,0 +1,3 @@
+-- 000005_create_sessions_table.down.sql
+DROP TABLE refresh_tokens;
+DROP TABLE sessions;
@@ -0,0 +1,2 @@
+-- 000006_create_workout_sets_table.down.sql
+DROP TABLE workout_sets;
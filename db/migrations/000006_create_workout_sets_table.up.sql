@@ -0,0 +1,16 @@
+-- 000006_create_workout_sets_table.up.sql
+CREATE TABLE workout_sets (
+    id SERIAL PRIMARY KEY,
+    workout_exercise_id INTEGER NOT NULL REFERENCES workout_exercises(id) ON DELETE CASCADE,
+    set_number INTEGER NOT NULL,
+    reps INTEGER NOT NULL DEFAULT 0,
+    weight DECIMAL(6,2) NOT NULL DEFAULT 0,
+    rpe DECIMAL(3,1) CHECK (rpe BETWEEN 1 AND 10),
+    rir INTEGER CHECK (rir BETWEEN 0 AND 10),
+    tempo VARCHAR(10) NOT NULL DEFAULT '',
+    set_type VARCHAR(10) NOT NULL DEFAULT 'working' CHECK (set_type IN ('warmup', 'working', 'drop', 'failure')),
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (workout_exercise_id, set_number)
+);
@@ -0,0 +1,6 @@
+-- 000007_add_workout_status.down.sql
+DROP INDEX idx_workouts_user_status;
+ALTER TABLE workouts
+    DROP COLUMN completed_at,
+    DROP COLUMN started_at,
+    DROP COLUMN status;
@@ -0,0 +1,13 @@
+-- 000007_add_workout_status.up.sql
+ALTER TABLE workouts
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'scheduled'
+        CHECK (status IN ('scheduled', 'in_progress', 'completed', 'skipped')),
+    ADD COLUMN started_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN completed_at TIMESTAMP WITH TIME ZONE;
+
+-- Workouts logged before statuses existed are treated as done.
+UPDATE workouts
+SET status = 'completed', completed_at = scheduled_for
+WHERE scheduled_for < CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_workouts_user_status ON workouts(user_id, status);
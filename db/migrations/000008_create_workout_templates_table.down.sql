@@ -0,0 +1,3 @@
+-- 000008_create_workout_templates_table.down.sql
+DROP TABLE workout_template_exercises;
+DROP TABLE workout_templates;
@@ -0,0 +1,21 @@
+-- 000008_create_workout_templates_table.up.sql
+CREATE TABLE workout_templates (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE workout_template_exercises (
+    id SERIAL PRIMARY KEY,
+    template_id INTEGER NOT NULL REFERENCES workout_templates(id) ON DELETE CASCADE,
+    exercise_id INTEGER NOT NULL REFERENCES exercises(id),
+    position INTEGER NOT NULL,
+    target_sets INTEGER NOT NULL,
+    target_reps INTEGER NOT NULL,
+    target_weight DECIMAL(6,2),
+    notes TEXT,
+    UNIQUE (template_id, position)
+);
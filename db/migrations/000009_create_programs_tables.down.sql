@@ -0,0 +1,6 @@
+-- 000009_create_programs_tables.down.sql
+DROP TABLE program_workouts;
+DROP TABLE program_enrollments;
+DROP TABLE program_day_exercises;
+DROP TABLE program_days;
+DROP TABLE programs;
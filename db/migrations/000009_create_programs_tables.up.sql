@@ -0,0 +1,50 @@
+-- 000009_create_programs_tables.up.sql
+CREATE TABLE programs (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    weeks INTEGER NOT NULL CHECK (weeks > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE program_days (
+    id SERIAL PRIMARY KEY,
+    program_id INTEGER NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    week INTEGER NOT NULL,
+    day INTEGER NOT NULL CHECK (day BETWEEN 1 AND 7),
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    UNIQUE (program_id, week, day)
+);
+
+CREATE TABLE program_day_exercises (
+    id SERIAL PRIMARY KEY,
+    program_day_id INTEGER NOT NULL REFERENCES program_days(id) ON DELETE CASCADE,
+    exercise_id INTEGER NOT NULL REFERENCES exercises(id),
+    position INTEGER NOT NULL,
+    sets INTEGER NOT NULL,
+    reps INTEGER NOT NULL,
+    rule VARCHAR(20) NOT NULL CHECK (rule IN ('linear', 'percentage', 'double_progression')),
+    start_weight DECIMAL(6,2) NOT NULL DEFAULT 0,
+    increment DECIMAL(5,2) NOT NULL DEFAULT 2.5,
+    training_max DECIMAL(6,2) NOT NULL DEFAULT 0,
+    percentages DOUBLE PRECISION[] NOT NULL DEFAULT '{}',
+    rep_range_min INTEGER NOT NULL DEFAULT 0,
+    rep_range_max INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (program_day_id, position)
+);
+
+CREATE TABLE program_enrollments (
+    id SERIAL PRIMARY KEY,
+    program_id INTEGER NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    start_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE program_workouts (
+    workout_id INTEGER PRIMARY KEY REFERENCES workouts(id) ON DELETE CASCADE,
+    enrollment_id INTEGER NOT NULL REFERENCES program_enrollments(id) ON DELETE CASCADE,
+    program_day_id INTEGER NOT NULL REFERENCES program_days(id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+-- 000010_create_personal_records_table.down.sql
+DROP TABLE personal_records;
@@ -0,0 +1,17 @@
+-- 000010_create_personal_records_table.up.sql
+CREATE TABLE personal_records (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    exercise_id INTEGER NOT NULL REFERENCES exercises(id),
+    workout_id INTEGER NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+    workout_exercise_id INTEGER NOT NULL REFERENCES workout_exercises(id) ON DELETE CASCADE,
+    record_type VARCHAR(20) NOT NULL
+        CHECK (record_type IN ('1rm', '3rm', '5rm', 'max_volume', 'max_reps_bodyweight')),
+    value DECIMAL(10,2) NOT NULL,
+    weight DECIMAL(6,2) NOT NULL DEFAULT 0,
+    reps INTEGER NOT NULL DEFAULT 0,
+    achieved_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_personal_records_lookup ON personal_records(user_id, exercise_id, record_type);
@@ -0,0 +1,2 @@
+-- 000011_create_calendar_feeds_table.down.sql
+DROP TABLE calendar_feeds;
@@ -0,0 +1,3 @@
+-- 000012_create_schedules_table.down.sql
+DROP TABLE schedule_workouts;
+DROP TABLE schedules;
@@ -0,0 +1,25 @@
+-- 000012_create_schedules_table.up.sql
+CREATE TABLE schedules (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    template_id INTEGER REFERENCES workout_templates(id) ON DELETE SET NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    timezone VARCHAR(64) NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    rrule TEXT NOT NULL,
+    exceptions DATE[] NOT NULL DEFAULT '{}',
+    generated_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_schedules_generated_until ON schedules(generated_until);
+
+CREATE TABLE schedule_workouts (
+    workout_id INTEGER PRIMARY KEY REFERENCES workouts(id) ON DELETE CASCADE,
+    schedule_id INTEGER NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    occurrence TIMESTAMP WITH TIME ZONE NOT NULL,
+    detached BOOLEAN NOT NULL DEFAULT FALSE,
+    UNIQUE (schedule_id, occurrence)
+);
@@ -0,0 +1,10 @@
+-- 000013_add_cascade_rules.down.sql
+ALTER TABLE workout_exercises
+    DROP CONSTRAINT workout_exercises_workout_id_fkey,
+    ADD CONSTRAINT workout_exercises_workout_id_fkey
+        FOREIGN KEY (workout_id) REFERENCES workouts(id);
+
+ALTER TABLE workouts
+    DROP CONSTRAINT workouts_user_id_fkey,
+    ADD CONSTRAINT workouts_user_id_fkey
+        FOREIGN KEY (user_id) REFERENCES users(id);
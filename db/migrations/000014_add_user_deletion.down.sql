@@ -0,0 +1,3 @@
+-- 000014_add_user_deletion.down.sql
+DROP INDEX idx_users_deletion_scheduled_at;
+ALTER TABLE users DROP COLUMN deletion_scheduled_at;
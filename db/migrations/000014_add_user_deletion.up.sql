@@ -0,0 +1,5 @@
+-- 000014_add_user_deletion.up.sql
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_users_deletion_scheduled_at ON users(deletion_scheduled_at)
+    WHERE deletion_scheduled_at IS NOT NULL;
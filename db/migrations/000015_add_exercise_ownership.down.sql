@@ -0,0 +1,7 @@
+-- 000015_add_exercise_ownership.down.sql
+ALTER TABLE users DROP COLUMN is_admin;
+
+DROP INDEX idx_exercises_owner_user_id;
+ALTER TABLE exercises
+    DROP COLUMN promotion_status,
+    DROP COLUMN owner_user_id;
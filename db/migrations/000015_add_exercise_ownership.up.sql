@@ -0,0 +1,10 @@
+-- 000015_add_exercise_ownership.up.sql
+-- Exercises without an owner make up the global catalog.
+ALTER TABLE exercises
+    ADD COLUMN owner_user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    ADD COLUMN promotion_status VARCHAR(20) NOT NULL DEFAULT 'none'
+        CHECK (promotion_status IN ('none', 'pending', 'approved', 'rejected'));
+
+CREATE INDEX idx_exercises_owner_user_id ON exercises(owner_user_id);
+
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+-- 000016_add_exercise_soft_delete.down.sql
+ALTER TABLE exercises DROP COLUMN deleted_at;
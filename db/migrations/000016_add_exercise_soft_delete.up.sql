@@ -0,0 +1,3 @@
+-- 000016_add_exercise_soft_delete.up.sql
+-- Exercises still used by workouts are hidden rather than deleted.
+ALTER TABLE exercises ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,9 @@
+-- 000017_add_exercise_search.down.sql
+DROP INDEX idx_exercises_name_id;
+DROP INDEX idx_exercises_name_trgm;
+DROP INDEX idx_exercises_search_vector;
+
+ALTER TABLE exercises
+    DROP COLUMN search_vector,
+    DROP COLUMN equipment,
+    DROP COLUMN muscle_group;
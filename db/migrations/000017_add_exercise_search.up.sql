@@ -0,0 +1,14 @@
+-- 000017_add_exercise_search.up.sql
+-- Trigram matching catches misspellings that full-text search misses.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE exercises
+    ADD COLUMN muscle_group VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN equipment VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('english', name || ' ' || coalesce(description, ''))
+    ) STORED;
+
+CREATE INDEX idx_exercises_search_vector ON exercises USING GIN (search_vector);
+CREATE INDEX idx_exercises_name_trgm ON exercises USING GIN (lower(name) gin_trgm_ops);
+CREATE INDEX idx_exercises_name_id ON exercises(lower(name), id);
@@ -0,0 +1,25 @@
+-- 000018_add_exercise_taxonomy.down.sql
+ALTER TABLE exercises
+    DROP COLUMN laterality,
+    DROP COLUMN movement_pattern,
+    ADD COLUMN muscle_group VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN equipment VARCHAR(50) NOT NULL DEFAULT '';
+
+UPDATE exercises e
+SET muscle_group = COALESCE((
+        SELECT mg.slug FROM exercise_muscle_groups emg
+        JOIN muscle_groups mg ON mg.id = emg.muscle_group_id
+        WHERE emg.exercise_id = e.id AND emg.role = 'primary'
+        ORDER BY mg.slug LIMIT 1
+    ), ''),
+    equipment = COALESCE((
+        SELECT eq.slug FROM exercise_equipment ee
+        JOIN equipment eq ON eq.id = ee.equipment_id
+        WHERE ee.exercise_id = e.id
+        ORDER BY eq.slug LIMIT 1
+    ), '');
+
+DROP TABLE exercise_equipment;
+DROP TABLE exercise_muscle_groups;
+DROP TABLE equipment;
+DROP TABLE muscle_groups;
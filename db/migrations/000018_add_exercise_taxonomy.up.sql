@@ -0,0 +1,77 @@
+-- 000018_add_exercise_taxonomy.up.sql
+CREATE TABLE muscle_groups (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL
+);
+
+INSERT INTO muscle_groups (slug, name) VALUES
+    ('chest', 'Chest'),
+    ('lats', 'Lats'),
+    ('upper_back', 'Upper Back'),
+    ('traps', 'Traps'),
+    ('lower_back', 'Lower Back'),
+    ('front_delts', 'Front Delts'),
+    ('side_delts', 'Side Delts'),
+    ('rear_delts', 'Rear Delts'),
+    ('biceps', 'Biceps'),
+    ('triceps', 'Triceps'),
+    ('forearms', 'Forearms'),
+    ('abs', 'Abs'),
+    ('obliques', 'Obliques'),
+    ('glutes', 'Glutes'),
+    ('quadriceps', 'Quadriceps'),
+    ('hamstrings', 'Hamstrings'),
+    ('adductors', 'Adductors'),
+    ('abductors', 'Abductors'),
+    ('calves', 'Calves');
+
+CREATE TABLE equipment (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL
+);
+
+INSERT INTO equipment (slug, name) VALUES
+    ('barbell', 'Barbell'),
+    ('dumbbell', 'Dumbbell'),
+    ('cable', 'Cable'),
+    ('machine', 'Machine'),
+    ('bodyweight', 'Bodyweight'),
+    ('band', 'Band');
+
+CREATE TABLE exercise_muscle_groups (
+    exercise_id INTEGER NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    muscle_group_id INTEGER NOT NULL REFERENCES muscle_groups(id),
+    role VARCHAR(10) NOT NULL CHECK (role IN ('primary', 'secondary')),
+    PRIMARY KEY (exercise_id, muscle_group_id)
+);
+
+CREATE INDEX idx_exercise_muscle_groups_muscle_group_id ON exercise_muscle_groups(muscle_group_id);
+
+CREATE TABLE exercise_equipment (
+    exercise_id INTEGER NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    equipment_id INTEGER NOT NULL REFERENCES equipment(id),
+    PRIMARY KEY (exercise_id, equipment_id)
+);
+
+CREATE INDEX idx_exercise_equipment_equipment_id ON exercise_equipment(equipment_id);
+
+-- Keep the free-text values that name a known muscle group or equipment.
+INSERT INTO exercise_muscle_groups (exercise_id, muscle_group_id, role)
+SELECT e.id, mg.id, 'primary'
+FROM exercises e
+JOIN muscle_groups mg ON mg.slug = replace(lower(trim(e.muscle_group)), ' ', '_');
+
+INSERT INTO exercise_equipment (exercise_id, equipment_id)
+SELECT e.id, eq.id
+FROM exercises e
+JOIN equipment eq ON eq.slug = lower(trim(e.equipment));
+
+ALTER TABLE exercises
+    DROP COLUMN muscle_group,
+    DROP COLUMN equipment,
+    ADD COLUMN movement_pattern VARCHAR(20) NOT NULL DEFAULT ''
+        CHECK (movement_pattern IN ('', 'squat', 'hinge', 'push', 'pull', 'carry')),
+    ADD COLUMN laterality VARCHAR(20) NOT NULL DEFAULT ''
+        CHECK (laterality IN ('', 'bilateral', 'unilateral'));
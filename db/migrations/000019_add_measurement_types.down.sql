@@ -0,0 +1,12 @@
+-- 000019_add_measurement_types.down.sql
+ALTER TABLE workout_sets
+    DROP COLUMN duration,
+    DROP COLUMN distance;
+
+ALTER TABLE workout_exercises
+    DROP COLUMN duration,
+    DROP COLUMN distance,
+    ALTER COLUMN reps DROP DEFAULT,
+    ALTER COLUMN sets DROP DEFAULT;
+
+ALTER TABLE exercises DROP COLUMN measurement_type;
@@ -0,0 +1,16 @@
+-- 000019_add_measurement_types.up.sql
+ALTER TABLE exercises
+    ADD COLUMN measurement_type VARCHAR(20) NOT NULL DEFAULT 'weight_reps'
+        CHECK (measurement_type IN ('weight_reps', 'reps', 'duration', 'distance_duration', 'weight_distance', 'weight_duration'));
+
+-- Distances are in metres and durations in seconds. Values an exercise's
+-- measurement type does not track are left at zero.
+ALTER TABLE workout_exercises
+    ALTER COLUMN sets SET DEFAULT 0,
+    ALTER COLUMN reps SET DEFAULT 0,
+    ADD COLUMN distance DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN duration INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE workout_sets
+    ADD COLUMN distance DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN duration INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- 000020_add_exercise_slugs.down.sql
+ALTER TABLE exercises DROP COLUMN slug;
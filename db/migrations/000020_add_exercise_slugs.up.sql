@@ -0,0 +1,4 @@
+-- 000020_add_exercise_slugs.up.sql
+-- Exercises seeded from the built-in catalog carry a stable slug so the
+-- catalog can be re-applied. Exercises created through the API have none.
+ALTER TABLE exercises ADD COLUMN slug VARCHAR(100) UNIQUE;
@@ -0,0 +1,7 @@
+-- 000021_add_workout_exercise_groups.down.sql
+ALTER TABLE workout_exercises
+    DROP CONSTRAINT workout_exercises_workout_id_position_key,
+    DROP COLUMN group_id,
+    DROP COLUMN position;
+
+DROP TABLE workout_exercise_groups;
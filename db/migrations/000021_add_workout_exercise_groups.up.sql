@@ -0,0 +1,32 @@
+-- 000021_add_workout_exercise_groups.up.sql
+-- Exercises of a workout can be grouped into a superset, giant set or
+-- circuit, performed together for a number of rounds with a rest between
+-- rounds. Labels such as A or B name a group within its workout.
+CREATE TABLE workout_exercise_groups (
+    id SERIAL PRIMARY KEY,
+    workout_id INTEGER NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+    label VARCHAR(10) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('superset', 'giant_set', 'circuit')),
+    rounds INTEGER NOT NULL DEFAULT 1 CHECK (rounds >= 1),
+    rest_seconds INTEGER NOT NULL DEFAULT 0 CHECK (rest_seconds >= 0),
+    UNIQUE (workout_id, label)
+);
+
+ALTER TABLE workout_exercises
+    ADD COLUMN position INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN group_id INTEGER REFERENCES workout_exercise_groups(id) ON DELETE SET NULL;
+
+-- Existing entries keep the order they were inserted in.
+UPDATE workout_exercises we
+SET position = numbered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY workout_id ORDER BY id) AS position
+    FROM workout_exercises
+) numbered
+WHERE we.id = numbered.id;
+
+ALTER TABLE workout_exercises
+    ADD CONSTRAINT workout_exercises_workout_id_position_key
+        UNIQUE (workout_id, position) DEFERRABLE INITIALLY DEFERRED;
+
+CREATE INDEX idx_workout_exercises_group_id ON workout_exercises(group_id);
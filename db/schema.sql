@@ -1,40 +1,303 @@
 -- schema.sql
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 CREATE TABLE users (
     id SERIAL PRIMARY KEY,
     username VARCHAR(50) UNIQUE NOT NULL,
     email VARCHAR(100) UNIQUE NOT NULL,
     password_hash VARCHAR(255) NOT NULL,
+    is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+    deletion_scheduled_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE INDEX idx_users_deletion_scheduled_at ON users(deletion_scheduled_at)
+    WHERE deletion_scheduled_at IS NOT NULL;
+
 CREATE TABLE exercises (
     id SERIAL PRIMARY KEY,
+    slug VARCHAR(100) UNIQUE,
     name VARCHAR(100) NOT NULL,
     description TEXT,
     category VARCHAR(50) NOT NULL,
+    measurement_type VARCHAR(20) NOT NULL DEFAULT 'weight_reps'
+        CHECK (measurement_type IN ('weight_reps', 'reps', 'duration', 'distance_duration', 'weight_distance', 'weight_duration')),
+    movement_pattern VARCHAR(20) NOT NULL DEFAULT ''
+        CHECK (movement_pattern IN ('', 'squat', 'hinge', 'push', 'pull', 'carry')),
+    laterality VARCHAR(20) NOT NULL DEFAULT ''
+        CHECK (laterality IN ('', 'bilateral', 'unilateral')),
+    owner_user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    promotion_status VARCHAR(20) NOT NULL DEFAULT 'none'
+        CHECK (promotion_status IN ('none', 'pending', 'approved', 'rejected')),
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('english', name || ' ' || coalesce(description, ''))
+    ) STORED,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE INDEX idx_exercises_owner_user_id ON exercises(owner_user_id);
+CREATE INDEX idx_exercises_search_vector ON exercises USING GIN (search_vector);
+CREATE INDEX idx_exercises_name_trgm ON exercises USING GIN (lower(name) gin_trgm_ops);
+CREATE INDEX idx_exercises_name_id ON exercises(lower(name), id);
+
+CREATE TABLE muscle_groups (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL
+);
+
+INSERT INTO muscle_groups (slug, name) VALUES
+    ('chest', 'Chest'),
+    ('lats', 'Lats'),
+    ('upper_back', 'Upper Back'),
+    ('traps', 'Traps'),
+    ('lower_back', 'Lower Back'),
+    ('front_delts', 'Front Delts'),
+    ('side_delts', 'Side Delts'),
+    ('rear_delts', 'Rear Delts'),
+    ('biceps', 'Biceps'),
+    ('triceps', 'Triceps'),
+    ('forearms', 'Forearms'),
+    ('abs', 'Abs'),
+    ('obliques', 'Obliques'),
+    ('glutes', 'Glutes'),
+    ('quadriceps', 'Quadriceps'),
+    ('hamstrings', 'Hamstrings'),
+    ('adductors', 'Adductors'),
+    ('abductors', 'Abductors'),
+    ('calves', 'Calves');
+
+CREATE TABLE equipment (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL
+);
+
+INSERT INTO equipment (slug, name) VALUES
+    ('barbell', 'Barbell'),
+    ('dumbbell', 'Dumbbell'),
+    ('cable', 'Cable'),
+    ('machine', 'Machine'),
+    ('bodyweight', 'Bodyweight'),
+    ('band', 'Band');
+
+CREATE TABLE exercise_muscle_groups (
+    exercise_id INTEGER NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    muscle_group_id INTEGER NOT NULL REFERENCES muscle_groups(id),
+    role VARCHAR(10) NOT NULL CHECK (role IN ('primary', 'secondary')),
+    PRIMARY KEY (exercise_id, muscle_group_id)
+);
+
+CREATE INDEX idx_exercise_muscle_groups_muscle_group_id ON exercise_muscle_groups(muscle_group_id);
+
+CREATE TABLE exercise_equipment (
+    exercise_id INTEGER NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    equipment_id INTEGER NOT NULL REFERENCES equipment(id),
+    PRIMARY KEY (exercise_id, equipment_id)
+);
+
+CREATE INDEX idx_exercise_equipment_equipment_id ON exercise_equipment(equipment_id);
+
 CREATE TABLE workouts (
     id SERIAL PRIMARY KEY,
-    user_id INTEGER REFERENCES users(id),
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
     name VARCHAR(100) NOT NULL,
     description TEXT,
     scheduled_for TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled'
+        CHECK (status IN ('scheduled', 'in_progress', 'completed', 'skipped')),
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE INDEX idx_workouts_user_status ON workouts(user_id, status);
+
+CREATE TABLE workout_exercise_groups (
+    id SERIAL PRIMARY KEY,
+    workout_id INTEGER NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+    label VARCHAR(10) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('superset', 'giant_set', 'circuit')),
+    rounds INTEGER NOT NULL DEFAULT 1 CHECK (rounds >= 1),
+    rest_seconds INTEGER NOT NULL DEFAULT 0 CHECK (rest_seconds >= 0),
+    UNIQUE (workout_id, label)
+);
+
 CREATE TABLE workout_exercises (
     id SERIAL PRIMARY KEY,
-    workout_id INTEGER REFERENCES workouts(id),
+    workout_id INTEGER REFERENCES workouts(id) ON DELETE CASCADE,
     exercise_id INTEGER REFERENCES exercises(id),
-    sets INTEGER NOT NULL,
-    reps INTEGER NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    group_id INTEGER REFERENCES workout_exercise_groups(id) ON DELETE SET NULL,
+    sets INTEGER NOT NULL DEFAULT 0,
+    reps INTEGER NOT NULL DEFAULT 0,
     weight DECIMAL(5,2),
+    distance DECIMAL(10,2) NOT NULL DEFAULT 0,
+    duration INTEGER NOT NULL DEFAULT 0,
     notes TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (workout_id, position) DEFERRABLE INITIALLY DEFERRED
+);
+
+CREATE INDEX idx_workout_exercises_group_id ON workout_exercises(group_id);
+
+CREATE TABLE sessions (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    session_id VARCHAR(32) NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_session_id ON refresh_tokens(session_id);
+
+CREATE TABLE workout_sets (
+    id SERIAL PRIMARY KEY,
+    workout_exercise_id INTEGER NOT NULL REFERENCES workout_exercises(id) ON DELETE CASCADE,
+    set_number INTEGER NOT NULL,
+    reps INTEGER NOT NULL DEFAULT 0,
+    weight DECIMAL(6,2) NOT NULL DEFAULT 0,
+    distance DECIMAL(10,2) NOT NULL DEFAULT 0,
+    duration INTEGER NOT NULL DEFAULT 0,
+    rpe DECIMAL(3,1) CHECK (rpe BETWEEN 1 AND 10),
+    rir INTEGER CHECK (rir BETWEEN 0 AND 10),
+    tempo VARCHAR(10) NOT NULL DEFAULT '',
+    set_type VARCHAR(10) NOT NULL DEFAULT 'working' CHECK (set_type IN ('warmup', 'working', 'drop', 'failure')),
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (workout_exercise_id, set_number)
+);
+
+CREATE TABLE workout_templates (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
+
+CREATE TABLE workout_template_exercises (
+    id SERIAL PRIMARY KEY,
+    template_id INTEGER NOT NULL REFERENCES workout_templates(id) ON DELETE CASCADE,
+    exercise_id INTEGER NOT NULL REFERENCES exercises(id),
+    position INTEGER NOT NULL,
+    target_sets INTEGER NOT NULL,
+    target_reps INTEGER NOT NULL,
+    target_weight DECIMAL(6,2),
+    notes TEXT,
+    UNIQUE (template_id, position)
+);
+
+CREATE TABLE programs (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    weeks INTEGER NOT NULL CHECK (weeks > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE program_days (
+    id SERIAL PRIMARY KEY,
+    program_id INTEGER NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    week INTEGER NOT NULL,
+    day INTEGER NOT NULL CHECK (day BETWEEN 1 AND 7),
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    UNIQUE (program_id, week, day)
+);
+
+CREATE TABLE program_day_exercises (
+    id SERIAL PRIMARY KEY,
+    program_day_id INTEGER NOT NULL REFERENCES program_days(id) ON DELETE CASCADE,
+    exercise_id INTEGER NOT NULL REFERENCES exercises(id),
+    position INTEGER NOT NULL,
+    sets INTEGER NOT NULL,
+    reps INTEGER NOT NULL,
+    rule VARCHAR(20) NOT NULL CHECK (rule IN ('linear', 'percentage', 'double_progression')),
+    start_weight DECIMAL(6,2) NOT NULL DEFAULT 0,
+    increment DECIMAL(5,2) NOT NULL DEFAULT 2.5,
+    training_max DECIMAL(6,2) NOT NULL DEFAULT 0,
+    percentages DOUBLE PRECISION[] NOT NULL DEFAULT '{}',
+    rep_range_min INTEGER NOT NULL DEFAULT 0,
+    rep_range_max INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (program_day_id, position)
+);
+
+CREATE TABLE program_enrollments (
+    id SERIAL PRIMARY KEY,
+    program_id INTEGER NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    start_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE program_workouts (
+    workout_id INTEGER PRIMARY KEY REFERENCES workouts(id) ON DELETE CASCADE,
+    enrollment_id INTEGER NOT NULL REFERENCES program_enrollments(id) ON DELETE CASCADE,
+    program_day_id INTEGER NOT NULL REFERENCES program_days(id) ON DELETE CASCADE
+);
+
+CREATE TABLE personal_records (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    exercise_id INTEGER NOT NULL REFERENCES exercises(id),
+    workout_id INTEGER NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+    workout_exercise_id INTEGER NOT NULL REFERENCES workout_exercises(id) ON DELETE CASCADE,
+    record_type VARCHAR(20) NOT NULL
+        CHECK (record_type IN ('1rm', '3rm', '5rm', 'max_volume', 'max_reps_bodyweight')),
+    value DECIMAL(10,2) NOT NULL,
+    weight DECIMAL(6,2) NOT NULL DEFAULT 0,
+    reps INTEGER NOT NULL DEFAULT 0,
+    achieved_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_personal_records_lookup ON personal_records(user_id, exercise_id, record_type);
+
+CREATE TABLE calendar_feeds (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token CHAR(64) UNIQUE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE schedules (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    template_id INTEGER REFERENCES workout_templates(id) ON DELETE SET NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    timezone VARCHAR(64) NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    rrule TEXT NOT NULL,
+    exceptions DATE[] NOT NULL DEFAULT '{}',
+    generated_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_schedules_generated_until ON schedules(generated_until);
+
+CREATE TABLE schedule_workouts (
+    workout_id INTEGER PRIMARY KEY REFERENCES workouts(id) ON DELETE CASCADE,
+    schedule_id INTEGER NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    occurrence TIMESTAMP WITH TIME ZONE NOT NULL,
+    detached BOOLEAN NOT NULL DEFAULT FALSE,
+    UNIQUE (schedule_id, occurrence)
+);
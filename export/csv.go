@@ -0,0 +1,97 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/yeboahd24/workout-tracker/model"
+)
+
+var csvHeader = []string{
+	"workout_id", "workout_name", "date", "status",
+	"exercise_id", "exercise_name", "category",
+	"set_number", "set_type", "sets", "reps", "weight", "distance", "duration", "rpe", "rir", "tempo", "completed",
+	"volume", "notes",
+}
+
+// WriteReportCSV writes one row per logged set. Exercises logged only as a
+// sets×reps×weight summary get a single row, and workouts without exercises
+// get a row with the exercise columns left empty.
+func WriteReportCSV(w io.Writer, report *model.Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, workout := range report.Workouts {
+		base := []string{
+			strconv.Itoa(workout.ID), workout.Name,
+			workout.ScheduledFor.Format("2006-01-02"), string(workout.Status),
+		}
+
+		if len(workout.Exercises) == 0 {
+			if err := cw.Write(append(base, make([]string, len(csvHeader)-len(base))...)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, entry := range workout.Exercises {
+			exercise := append(append([]string{}, base...),
+				strconv.Itoa(entry.ExerciseID), entry.ExerciseName, entry.Category,
+			)
+
+			if len(entry.SetLog) == 0 {
+				row := append(exercise,
+					"", "", strconv.Itoa(entry.Sets), strconv.Itoa(entry.Reps), formatFloat(entry.Weight),
+					formatFloat(entry.Distance), strconv.Itoa(entry.Duration),
+					"", "", "", "", formatFloat(entry.Volume), entry.Notes,
+				)
+				if err := cw.Write(row); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for _, set := range entry.SetLog {
+				var volume float64
+				if set.CountsTowardVolume() {
+					volume = float64(set.Reps) * set.Weight
+				}
+
+				row := append(append([]string{}, exercise...),
+					strconv.Itoa(set.SetNumber), set.SetType, "1",
+					strconv.Itoa(set.Reps), formatFloat(set.Weight),
+					formatFloat(set.Distance), strconv.Itoa(set.Duration),
+					optionalFloat(set.RPE), optionalInt(set.RIR), set.Tempo,
+					strconv.FormatBool(set.Completed), formatFloat(volume), entry.Notes,
+				)
+				if err := cw.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func optionalFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return formatFloat(*v)
+}
+
+func optionalInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
@@ -0,0 +1,157 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yeboahd24/workout-tracker/model"
+)
+
+// defaultEventDuration is used for workouts that have not been finished.
+const defaultEventDuration = time.Hour
+
+const icalTime = "20060102T150405Z"
+
+// WriteCalendar writes the workouts as an iCalendar (RFC 5545) feed. Each
+// workout keeps the same UID for its lifetime and its SEQUENCE grows with
+// updated_at, so subscribed calendars pick up edits, and workouts that
+// disappear from the feed are removed. exerciseNames maps exercise IDs to
+// the names listed in each event's description.
+func WriteCalendar(w io.Writer, name string, workouts []*model.Workout, exerciseNames map[int]string) error {
+	cw := &icalWriter{w: bufio.NewWriter(w)}
+
+	cw.line("BEGIN:VCALENDAR")
+	cw.line("VERSION:2.0")
+	cw.line("PRODID:-//workout-tracker//Workouts//EN")
+	cw.line("CALSCALE:GREGORIAN")
+	cw.line("METHOD:PUBLISH")
+	cw.line("X-WR-CALNAME:" + icalText(name))
+	cw.line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	cw.line("X-PUBLISHED-TTL:PT1H")
+
+	for _, workout := range workouts {
+		start := workout.ScheduledFor.UTC()
+		end := start.Add(defaultEventDuration)
+		if workout.StartedAt != nil && workout.CompletedAt != nil && workout.CompletedAt.After(*workout.StartedAt) {
+			start = workout.StartedAt.UTC()
+			end = workout.CompletedAt.UTC()
+		}
+
+		status := "CONFIRMED"
+		if workout.Status == model.StatusSkipped {
+			status = "CANCELLED"
+		}
+
+		cw.line("BEGIN:VEVENT")
+		cw.line(fmt.Sprintf("UID:workout-%d@workout-tracker", workout.ID))
+		cw.line("SEQUENCE:" + strconv.FormatInt(eventSequence(workout), 10))
+		cw.line("DTSTAMP:" + workout.UpdatedAt.UTC().Format(icalTime))
+		cw.line("CREATED:" + workout.CreatedAt.UTC().Format(icalTime))
+		cw.line("LAST-MODIFIED:" + workout.UpdatedAt.UTC().Format(icalTime))
+		cw.line("DTSTART:" + start.Format(icalTime))
+		cw.line("DTEND:" + end.Format(icalTime))
+		cw.line("SUMMARY:" + icalText(workout.Name))
+		if description := eventDescription(workout, exerciseNames); description != "" {
+			cw.line("DESCRIPTION:" + icalText(description))
+		}
+		cw.line("STATUS:" + status)
+		cw.line("END:VEVENT")
+	}
+
+	cw.line("END:VCALENDAR")
+	if cw.err != nil {
+		return cw.err
+	}
+	return cw.w.Flush()
+}
+
+// eventSequence is the number of seconds between a workout's creation and
+// its last update, which only grows as the workout is edited.
+func eventSequence(workout *model.Workout) int64 {
+	if seq := workout.UpdatedAt.Unix() - workout.CreatedAt.Unix(); seq > 0 {
+		return seq
+	}
+	return 0
+}
+
+func eventDescription(workout *model.Workout, exerciseNames map[int]string) string {
+	var lines []string
+	if workout.Description != "" {
+		lines = append(lines, workout.Description, "")
+	}
+
+	for _, e := range workout.Exercises {
+		name, ok := exerciseNames[e.ExerciseID]
+		if !ok {
+			name = fmt.Sprintf("Exercise %d", e.ExerciseID)
+		}
+
+		amount := strconv.Itoa(e.Reps)
+		if e.Reps == 0 && (e.Distance > 0 || e.Duration > 0) {
+			var parts []string
+			if e.Distance > 0 {
+				parts = append(parts, strconv.FormatFloat(e.Distance, 'f', -1, 64)+" m")
+			}
+			if e.Duration > 0 {
+				parts = append(parts, (time.Duration(e.Duration) * time.Second).String())
+			}
+			amount = strings.Join(parts, " in ")
+		}
+
+		line := fmt.Sprintf("%s: %d x %s", name, e.Sets, amount)
+		if e.Weight > 0 {
+			line += " @ " + strconv.FormatFloat(e.Weight, 'f', -1, 64)
+		}
+		if e.Notes != "" {
+			line += " (" + e.Notes + ")"
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// icalText escapes a TEXT value.
+func icalText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// icalWriter writes content lines with CRLF endings, folding them at 75
+// octets without splitting a UTF-8 character.
+type icalWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (cw *icalWriter) line(s string) {
+	if cw.err != nil {
+		return
+	}
+
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		if _, cw.err = cw.w.WriteString(s[:cut] + "\r\n "); cw.err != nil {
+			return
+		}
+		s = s[cut:]
+		// Continuation lines start with a space, which counts toward the limit.
+		limit = 74
+	}
+	_, cw.err = cw.w.WriteString(s + "\r\n")
+}
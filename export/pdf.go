@@ -0,0 +1,351 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+)
+
+// A4 in PDF points, with the margins used on every page.
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+	margin     = 50.0
+)
+
+// WriteReportPDF renders the report as a printable summary: headline
+// totals, a volume bar chart, per-exercise and per-category tables, best
+// sets and the list of workouts.
+func WriteReportPDF(w io.Writer, report *model.Report) error {
+	doc := newPDFDocument()
+
+	doc.heading("Workout Report")
+	doc.paragraph(fmt.Sprintf("%s to %s", report.StartDate, report.EndDate))
+	doc.space(10)
+
+	doc.subheading("Summary")
+	doc.table([]column{{"Metric", 250}, {"Value", 245}}, [][]string{
+		{"Workouts", fmt.Sprint(report.TotalWorkouts)},
+		{"Exercises logged", fmt.Sprint(report.TotalExercises)},
+		{"Sets", fmt.Sprint(report.TotalSets)},
+		{"Total volume", formatVolume(report.TotalVolume)},
+		{"Sessions per week", fmt.Sprintf("%.2f", report.SessionsPerWeek)},
+		{"Average density (volume/min)", fmt.Sprintf("%.2f", report.AverageDensity)},
+	})
+
+	doc.subheading("Volume")
+	doc.barChart(volumeSeries(report))
+
+	if len(report.VolumeByExercise) > 0 {
+		doc.subheading("Volume by exercise")
+		rows := make([][]string, 0, len(report.VolumeByExercise))
+		for _, ev := range report.VolumeByExercise {
+			rows = append(rows, []string{ev.ExerciseName, fmt.Sprint(ev.Sets), formatVolume(ev.Volume)})
+		}
+		doc.table([]column{{"Exercise", 295}, {"Sets", 80}, {"Volume", 120}}, rows)
+	}
+
+	if len(report.VolumeByCategory) > 0 {
+		doc.subheading("Volume by category")
+		rows := make([][]string, 0, len(report.VolumeByCategory))
+		for _, cv := range report.VolumeByCategory {
+			rows = append(rows, []string{cv.Category, fmt.Sprint(cv.Sets), formatVolume(cv.Volume)})
+		}
+		doc.table([]column{{"Category", 295}, {"Sets", 80}, {"Volume", 120}}, rows)
+	}
+
+	if len(report.BestSets) > 0 {
+		doc.subheading("Best sets")
+		rows := make([][]string, 0, len(report.BestSets))
+		for _, bs := range report.BestSets {
+			rows = append(rows, []string{
+				bs.ExerciseName, bs.Date.Format("2006-01-02"),
+				fmt.Sprintf("%s x %d", formatVolume(bs.Weight), bs.Reps), formatVolume(bs.EstimatedOneRepMax),
+			})
+		}
+		doc.table([]column{{"Exercise", 215}, {"Date", 90}, {"Set", 100}, {"Est. 1RM", 90}}, rows)
+	}
+
+	doc.subheading("Workouts")
+	rows := make([][]string, 0, len(report.Workouts))
+	for _, workout := range report.Workouts {
+		rows = append(rows, []string{
+			workout.ScheduledFor.Format("2006-01-02"), workout.Name, string(workout.Status),
+			fmt.Sprint(len(workout.Exercises)), formatVolume(workout.Volume),
+		})
+	}
+	doc.table([]column{{"Date", 80}, {"Workout", 205}, {"Status", 80}, {"Exercises", 60}, {"Volume", 70}}, rows)
+
+	_, err := w.Write(doc.bytes())
+	return err
+}
+
+type barPoint struct {
+	label string
+	value float64
+}
+
+// volumeSeries charts the report's buckets when it is grouped, and each
+// workout otherwise.
+func volumeSeries(report *model.Report) []barPoint {
+	var points []barPoint
+	if len(report.Buckets) > 0 {
+		for _, b := range report.Buckets {
+			points = append(points, barPoint{b.Period.Format("01-02"), b.Volume})
+		}
+		return points
+	}
+	for _, workout := range report.Workouts {
+		points = append(points, barPoint{workout.ScheduledFor.Format("01-02"), workout.Volume})
+	}
+	return points
+}
+
+func formatVolume(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+}
+
+type column struct {
+	title string
+	width float64
+}
+
+// pdfDocument lays out text, tables and a chart top to bottom, starting a
+// new page whenever the next item would not fit.
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func newPDFDocument() *pdfDocument {
+	doc := &pdfDocument{}
+	doc.newPage()
+	return doc
+}
+
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - margin
+}
+
+func (d *pdfDocument) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// ensure starts a new page unless height points are left on this one.
+func (d *pdfDocument) ensure(height float64) {
+	if d.y-height < margin {
+		d.newPage()
+	}
+}
+
+func (d *pdfDocument) space(height float64) {
+	d.y -= height
+}
+
+func (d *pdfDocument) heading(text string) {
+	d.ensure(30)
+	d.y -= 22
+	d.text(margin, d.y, 20, true, text)
+	d.y -= 8
+}
+
+func (d *pdfDocument) subheading(text string) {
+	d.ensure(40)
+	d.y -= 24
+	d.text(margin, d.y, 13, true, text)
+	d.y -= 8
+}
+
+func (d *pdfDocument) paragraph(text string) {
+	d.ensure(16)
+	d.y -= 14
+	d.text(margin, d.y, 10, false, text)
+}
+
+// table draws a header row and the rows beneath it, repeating the header
+// when the table runs onto a new page.
+func (d *pdfDocument) table(columns []column, rows [][]string) {
+	const rowHeight = 16.0
+
+	header := func() {
+		d.ensure(rowHeight * 2)
+		d.fillRect(margin, d.y-rowHeight, tableWidth(columns), rowHeight, 0.88)
+		x := margin
+		for _, c := range columns {
+			d.text(x+4, d.y-rowHeight+5, 9, true, fit(c.title, c.width, 9))
+			x += c.width
+		}
+		d.y -= rowHeight
+	}
+
+	header()
+	for _, row := range rows {
+		if d.y-rowHeight < margin {
+			d.newPage()
+			header()
+		}
+		x := margin
+		for i, c := range columns {
+			if i < len(row) {
+				d.text(x+4, d.y-rowHeight+5, 9, false, fit(row[i], c.width, 9))
+			}
+			x += c.width
+		}
+		d.line(margin, d.y-rowHeight, margin+tableWidth(columns), d.y-rowHeight)
+		d.y -= rowHeight
+	}
+}
+
+// barChart draws one bar per point, scaled to the largest value.
+func (d *pdfDocument) barChart(points []barPoint) {
+	const chartHeight = 150.0
+	chartWidth := pageWidth - 2*margin
+
+	if len(points) == 0 {
+		d.paragraph("No workouts in this period.")
+		return
+	}
+
+	d.ensure(chartHeight + 30)
+	top := d.y - 10
+	bottom := top - chartHeight
+
+	var max float64
+	for _, p := range points {
+		if p.value > max {
+			max = p.value
+		}
+	}
+
+	d.line(margin, bottom, margin+chartWidth, bottom)
+	d.line(margin, bottom, margin, top)
+	d.text(margin+4, top-8, 8, false, formatVolume(max))
+
+	slot := chartWidth / float64(len(points))
+	barWidth := slot * 0.7
+	labelEvery := 1
+	if slot < 30 {
+		labelEvery = int(30/slot) + 1
+	}
+
+	for i, p := range points {
+		x := margin + float64(i)*slot + (slot-barWidth)/2
+		if max > 0 && p.value > 0 {
+			d.fillRect(x, bottom, barWidth, chartHeight*p.value/max, 0.35)
+		}
+		if i%labelEvery == 0 {
+			d.text(x, bottom-10, 7, false, p.label)
+		}
+	}
+
+	d.y = bottom - 16
+}
+
+func (d *pdfDocument) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escapePDFText(s))
+}
+
+func (d *pdfDocument) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page(), "0.5 w 0.6 G %.2f %.2f m %.2f %.2f l S 0 G\n", x1, y1, x2, y2)
+}
+
+func (d *pdfDocument) fillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(d.page(), "%.2f g %.2f %.2f %.2f %.2f re f 0 g\n", gray, x, y, w, h)
+}
+
+// bytes serializes the document: catalog, page tree, the two Helvetica
+// fonts, then a page object and content stream per page, followed by the
+// cross-reference table.
+func (d *pdfDocument) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	const firstPageObject = 5
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObject+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, content := range d.pages {
+		footer := fmt.Sprintf("BT /F1 8 Tf %.2f %.2f Td (Page %d of %d - generated %s) Tj ET\n",
+			margin, margin/2, i+1, len(d.pages), time.Now().UTC().Format("2006-01-02"))
+		stream := content.String() + footer
+
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, firstPageObject+2*i+1,
+		))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(stream), stream))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+func tableWidth(columns []column) float64 {
+	var width float64
+	for _, c := range columns {
+		width += c.width
+	}
+	return width
+}
+
+// fit truncates s so that it roughly fits in width points at the given font
+// size, using the average Helvetica glyph width.
+func fit(s string, width, size float64) string {
+	max := int((width - 8) / (size * 0.5))
+	runes := []rune(s)
+	if len(runes) <= max || max < 4 {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
+
+// escapePDFText escapes a string for a PDF literal string in WinAnsi
+// encoding. Characters outside Latin-1 are replaced with "?".
+func escapePDFText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32:
+			b.WriteByte(' ')
+		case r < 128:
+			b.WriteRune(r)
+		case r >= 160 && r < 256:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+	"github.com/yeboahd24/workout-tracker/service"
+	"github.com/yeboahd24/workout-tracker/util"
+)
+
+// maxArchiveSize bounds the size of an archive sent to /me/import.
+const maxArchiveSize = 100 << 20
+
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// Export sends the caller's data as a versioned JSON archive.
+func (h *AccountHandler) Export(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	archive, err := h.accountService.Export(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error exporting account: %v", err)
+		http.Error(w, "Failed to export account", http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("workout-tracker-%s-%s.json", archive.Profile.Username, archive.ExportedAt.Format(time.DateOnly))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	json.NewEncoder(w).Encode(archive)
+}
+
+// Import restores an archive produced by Export into the caller's account,
+// which must not have any workouts yet.
+func (h *AccountHandler) Import(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var archive model.Archive
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxArchiveSize)).Decode(&archive); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := archive.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.accountService.Import(r.Context(), userID, &archive)
+	if err != nil {
+		if errors.Is(err, model.ErrAccountNotEmpty) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if isInvalidTaxonomy(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error importing account: %v", err)
+		http.Error(w, "Failed to import account", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+// RequestDeletion schedules the caller's account for deletion. The password
+// has to be entered again. All sessions are revoked; logging in again and
+// calling CancelDeletion within the grace period keeps the account.
+func (h *AccountHandler) RequestDeletion(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input struct {
+		Password string `json:"password"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.accountService.RequestDeletion(r.Context(), userID, input.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrInvalidCredentials):
+			http.Error(w, "Invalid password", http.StatusUnauthorized)
+		case errors.Is(err, model.ErrDeletionPending):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			log.Printf("Error scheduling account deletion: %v", err)
+			http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"deletion_scheduled_at": user.DeletionScheduledAt,
+	})
+}
+
+func (h *AccountHandler) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if _, err := h.accountService.CancelDeletion(r.Context(), userID); err != nil {
+		if errors.Is(err, model.ErrDeletionNotPending) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("Error cancelling account deletion: %v", err)
+		http.Error(w, "Failed to cancel account deletion", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/yeboahd24/workout-tracker/model"
+	"github.com/yeboahd24/workout-tracker/repository"
+	"github.com/yeboahd24/workout-tracker/util"
+)
+
+type AnalyticsHandler struct {
+	workoutRepo *repository.WorkoutRepository
+}
+
+func NewAnalyticsHandler(workoutRepo *repository.WorkoutRepository) *AnalyticsHandler {
+	return &AnalyticsHandler{workoutRepo: workoutRepo}
+}
+
+// EstimatedOneRepMax returns a time series of the best estimated one-rep max
+// per exercise. Query parameters: exercise_id (optional), start_date and
+// end_date (YYYY-MM-DD, default the last 90 days), interval (day, week or month,
+// default week) and formula (epley, brzycki or lombardi, default epley).
+func (h *AnalyticsHandler) EstimatedOneRepMax(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+
+	var exerciseID int
+	if idStr := query.Get("exercise_id"); idStr != "" {
+		exerciseID, err = strconv.Atoi(idStr)
+		if err != nil {
+			http.Error(w, "Invalid exercise ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	formula, err := model.ParseOneRepMaxFormula(query.Get("formula"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	interval, err := model.ParseInterval(query.Get("interval"), model.IntervalWeek)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	start, end, err := parseDateRange(r, 90)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sets, err := h.workoutRepo.GetLoggedSets(r.Context(), userID, exerciseID, start, end)
+	if err != nil {
+		log.Printf("Error fetching logged sets: %v", err)
+		http.Error(w, "Failed to build strength series", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.BuildStrengthSeries(sets, formula, interval))
+}
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/yeboahd24/workout-tracker/model"
@@ -11,12 +13,13 @@ import (
 )
 
 type AuthHandler struct {
-	userRepo  *repository.UserRepository
-	jwtSecret string
+	userRepo    *repository.UserRepository
+	sessionRepo *repository.SessionRepository
+	jwtSecret   string
 }
 
-func NewAuthHandler(userRepo *repository.UserRepository, jwtSecret string) *AuthHandler {
-	return &AuthHandler{userRepo: userRepo, jwtSecret: jwtSecret}
+func NewAuthHandler(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, jwtSecret string) *AuthHandler {
+	return &AuthHandler{userRepo: userRepo, sessionRepo: sessionRepo, jwtSecret: jwtSecret}
 }
 
 func (h *AuthHandler) SignUp(w http.ResponseWriter, r *http.Request) {
@@ -43,8 +46,8 @@ func (h *AuthHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]string{"message": "User created successfully"})
 }
 
@@ -70,14 +73,108 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := util.GenerateJWT(user.ID, h.jwtSecret)
+	session, err := model.NewSession(user.ID)
+	if err != nil {
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	refreshToken, plainRefreshToken, err := model.NewRefreshToken(session.ID, user.ID)
+	if err != nil {
+		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.sessionRepo.Create(r.Context(), session, refreshToken); err != nil {
+		log.Printf("Error creating session: %v", err)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeTokens(w, user.ID, session.ID, plainRefreshToken)
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	current, err := h.sessionRepo.GetRefreshToken(r.Context(), model.HashRefreshToken(input.RefreshToken))
+	if err != nil {
+		http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
+		return
+	}
+
+	active, err := h.sessionRepo.IsActive(r.Context(), current.SessionID)
+	if err != nil {
+		log.Printf("Error checking session: %v", err)
+		http.Error(w, "Failed to refresh token", http.StatusInternalServerError)
+		return
+	}
+	if !active || current.IsExpired() {
+		http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
+		return
+	}
+
+	next, plainRefreshToken, err := model.NewRefreshToken(current.SessionID, current.UserID)
+	if err != nil {
+		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	err = h.sessionRepo.Rotate(r.Context(), current, next)
+	if errors.Is(err, repository.ErrRefreshTokenReused) {
+		// A rotated token showing up again means it leaked; kill the family.
+		if err := h.sessionRepo.Revoke(r.Context(), current.SessionID); err != nil {
+			log.Printf("Error revoking session: %v", err)
+		}
+		http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log.Printf("Error rotating refresh token: %v", err)
+		http.Error(w, "Failed to refresh token", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeTokens(w, current.UserID, current.SessionID, plainRefreshToken)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := util.GetSessionIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.sessionRepo.Revoke(r.Context(), sessionID); err != nil {
+		log.Printf("Error revoking session: %v", err)
+		http.Error(w, "Failed to log out", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) writeTokens(w http.ResponseWriter, userID int, sessionID, refreshToken string) {
+	token, err := util.GenerateJWT(userID, sessionID, h.jwtSecret)
 	if err != nil {
 		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
 		return
 	}
 
-	// Send the token as a JSON response
+	// Send the token pair as a JSON response
 	w.Header().Set("Content-Type", "application/json")
 
-	json.NewEncoder(w).Encode(map[string]string{"token": token})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(util.AccessTokenTTL.Seconds()),
+	})
 }
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/export"
+	"github.com/yeboahd24/workout-tracker/model"
+	"github.com/yeboahd24/workout-tracker/repository"
+	"github.com/yeboahd24/workout-tracker/util"
+)
+
+// calendarHistory is how far back the feed goes, so recent workouts stay in
+// subscribed calendars after they are done.
+const calendarHistory = 30 * 24 * time.Hour
+
+type CalendarHandler struct {
+	calendarRepo *repository.CalendarRepository
+	workoutRepo  *repository.WorkoutRepository
+	exerciseRepo *repository.ExerciseRepository
+}
+
+func NewCalendarHandler(calendarRepo *repository.CalendarRepository, workoutRepo *repository.WorkoutRepository, exerciseRepo *repository.ExerciseRepository) *CalendarHandler {
+	return &CalendarHandler{calendarRepo: calendarRepo, workoutRepo: workoutRepo, exerciseRepo: exerciseRepo}
+}
+
+// GetFeed returns the caller's feed URL, creating the feed on first use.
+func (h *CalendarHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	feed, err := h.calendarRepo.GetByUserID(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error fetching calendar feed: %v", err)
+		http.Error(w, "Failed to fetch calendar feed", http.StatusInternalServerError)
+		return
+	}
+	if feed == nil {
+		h.saveFeed(w, r, userID)
+		return
+	}
+
+	h.writeFeed(w, r, feed, http.StatusOK)
+}
+
+// RotateFeed replaces the caller's feed token, so the old URL stops working.
+func (h *CalendarHandler) RotateFeed(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	h.saveFeed(w, r, userID)
+}
+
+func (h *CalendarHandler) saveFeed(w http.ResponseWriter, r *http.Request, userID int) {
+	feed, err := model.NewCalendarFeed(userID)
+	if err != nil {
+		log.Printf("Error creating calendar feed: %v", err)
+		http.Error(w, "Failed to create calendar feed", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.calendarRepo.Save(r.Context(), feed); err != nil {
+		log.Printf("Error saving calendar feed: %v", err)
+		http.Error(w, "Failed to create calendar feed", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeFeed(w, r, feed, http.StatusCreated)
+}
+
+func (h *CalendarHandler) writeFeed(w http.ResponseWriter, r *http.Request, feed *model.CalendarFeed, status int) {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":      feed.Token,
+		"url":        fmt.Sprintf("%s://%s/calendar/%s.ics", scheme, r.Host, feed.Token),
+		"created_at": feed.CreatedAt,
+	})
+}
+
+// Calendar serves the iCalendar feed named by the token in the path. It is
+// not behind the auth middleware: the token is the credential.
+func (h *CalendarHandler) Calendar(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutSuffix(r.PathValue("file"), ".ics")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	feed, err := h.calendarRepo.GetByToken(r.Context(), token)
+	if err != nil {
+		log.Printf("Error fetching calendar feed: %v", err)
+		http.Error(w, "Failed to fetch calendar", http.StatusInternalServerError)
+		return
+	}
+	if feed == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	workouts, err := h.workoutRepo.GetCalendar(r.Context(), feed.UserID, time.Now().Add(-calendarHistory))
+	if err != nil {
+		log.Printf("Error fetching calendar workouts: %v", err)
+		http.Error(w, "Failed to fetch calendar", http.StatusInternalServerError)
+		return
+	}
+
+	exercises, err := h.exerciseRepo.GetAll(r.Context(), feed.UserID)
+	if err != nil {
+		log.Printf("Error fetching exercises: %v", err)
+		http.Error(w, "Failed to fetch calendar", http.StatusInternalServerError)
+		return
+	}
+	exerciseNames := make(map[int]string, len(exercises))
+	for _, e := range exercises {
+		exerciseNames[e.ID] = e.Name
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if err := export.WriteCalendar(w, "Workouts", workouts, exerciseNames); err != nil {
+		log.Printf("Error writing calendar: %v", err)
+	}
+}
@@ -2,70 +2,483 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/yeboahd24/workout-tracker/model"
 	"github.com/yeboahd24/workout-tracker/repository"
+	"github.com/yeboahd24/workout-tracker/util"
 )
 
 type ExerciseHandler struct {
 	exerciseRepo *repository.ExerciseRepository
+	userRepo     *repository.UserRepository
 }
 
-func NewExerciseHandler(exerciseRepo *repository.ExerciseRepository) *ExerciseHandler {
-	return &ExerciseHandler{exerciseRepo: exerciseRepo}
+func NewExerciseHandler(exerciseRepo *repository.ExerciseRepository, userRepo *repository.UserRepository) *ExerciseHandler {
+	return &ExerciseHandler{exerciseRepo: exerciseRepo, userRepo: userRepo}
 }
 
+// Create adds a custom exercise that only the caller can see.
 func (h *ExerciseHandler) Create(w http.ResponseWriter, r *http.Request) {
-	var input struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Category    string `json:"category"`
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
 	}
 
+	h.create(w, r, func(name, description, category string) *model.Exercise {
+		return model.NewCustomExercise(userID, name, description, category)
+	})
+}
+
+// CreateGlobal adds an exercise straight to the global catalog. Admin only.
+func (h *ExerciseHandler) CreateGlobal(w http.ResponseWriter, r *http.Request) {
+	h.create(w, r, model.NewExercise)
+}
+
+// exerciseInput is the body of exercise create and update requests.
+type exerciseInput struct {
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	Category         string   `json:"category"`
+	MeasurementType  string   `json:"measurement_type"`
+	PrimaryMuscles   []string `json:"primary_muscles"`
+	SecondaryMuscles []string `json:"secondary_muscles"`
+	Equipment        []string `json:"equipment"`
+	MovementPattern  string   `json:"movement_pattern"`
+	Laterality       string   `json:"laterality"`
+}
+
+// classify sets how the exercise is measured and classified.
+func (in *exerciseInput) classify(exercise *model.Exercise) error {
+	measurementType, err := model.ParseMeasurementType(in.MeasurementType)
+	if err != nil {
+		return err
+	}
+	exercise.MeasurementType = measurementType
+	return exercise.SetTaxonomy(in.PrimaryMuscles, in.SecondaryMuscles, in.Equipment, in.MovementPattern, in.Laterality)
+}
+
+func (h *ExerciseHandler) create(w http.ResponseWriter, r *http.Request, newExercise func(name, description, category string) *model.Exercise) {
+	var input exerciseInput
+
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	exercise := model.NewExercise(input.Name, input.Description, input.Category)
+	exercise := newExercise(input.Name, input.Description, input.Category)
+	if err := input.classify(exercise); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	if err := h.exerciseRepo.Create(r.Context(), exercise); err != nil {
+		if isInvalidTaxonomy(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error creating exercise: %v", err)
 		http.Error(w, "Failed to create exercise", http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(exercise)
 }
 
+// GetByID also answers for deleted exercises, since old workouts still refer
+// to them.
 func (h *ExerciseHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Query().Get("id")
-	id, err := strconv.Atoi(idStr)
+	exercise, ok := h.visibleExercise(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(exercise)
+}
+
+// Taxonomy lists the muscle groups, equipment, movement patterns and
+// lateralities exercises can be classified with.
+func (h *ExerciseHandler) Taxonomy(w http.ResponseWriter, r *http.Request) {
+	taxonomy, err := h.exerciseRepo.GetTaxonomy(r.Context())
 	if err != nil {
-		http.Error(w, "Invalid exercise ID", http.StatusBadRequest)
+		log.Printf("Error fetching exercise taxonomy: %v", err)
+		http.Error(w, "Failed to fetch taxonomy", http.StatusInternalServerError)
 		return
 	}
 
-	exercise, err := h.exerciseRepo.GetByID(r.Context(), id)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(taxonomy)
+}
+
+// GetAll returns a page of the global catalog and the caller's custom
+// exercises, optionally narrowed by the q, category, muscle_group,
+// equipment, movement_pattern and laterality query parameters. Pass
+// next_cursor back as cursor for the next page.
+func (h *ExerciseHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
 	if err != nil {
-		http.Error(w, "Exercise not found", http.StatusNotFound)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	search := model.ExerciseSearch{
+		Query:           strings.TrimSpace(query.Get("q")),
+		Category:        query.Get("category"),
+		MuscleGroup:     query.Get("muscle_group"),
+		Equipment:       query.Get("equipment"),
+		MovementPattern: query.Get("movement_pattern"),
+		Laterality:      query.Get("laterality"),
+		Limit:           model.DefaultExercisePageSize,
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		search.Limit, err = strconv.Atoi(limit)
+		if err != nil || search.Limit < 1 || search.Limit > model.MaxExercisePageSize {
+			http.Error(w, model.ErrInvalidPageSize.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		search.After, err = model.ParseExerciseCursor(cursor)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.exerciseRepo.Search(r.Context(), userID, search)
+	if err != nil {
+		log.Printf("Error searching exercises: %v", err)
+		http.Error(w, "Failed to fetch exercises", http.StatusInternalServerError)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 
+	json.NewEncoder(w).Encode(page)
+}
+
+// Update edits a custom exercise owned by the caller, or a catalog exercise
+// if the caller is an admin.
+func (h *ExerciseHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		ID int `json:"id"`
+		exerciseInput
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The deprecated route takes the ID in the body
+	if idStr := r.PathValue("id"); idStr != "" {
+		var err error
+		if input.ID, err = strconv.Atoi(idStr); err != nil {
+			http.Error(w, "Invalid exercise ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	exercise, ok := h.editableExercise(w, r, input.ID)
+	if !ok {
+		return
+	}
+
+	exercise.Name = input.Name
+	exercise.Description = input.Description
+	exercise.Category = input.Category
+	exercise.UpdatedAt = time.Now()
+	if err := input.classify(exercise); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.exerciseRepo.Update(r.Context(), exercise); err != nil {
+		if isInvalidTaxonomy(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error updating exercise: %v", err)
+		http.Error(w, "Failed to update exercise", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(exercise)
 }
 
-func (h *ExerciseHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	exercises, err := h.exerciseRepo.GetAll(r.Context())
+// Delete removes an exercise. One that workouts, templates, programs or
+// records still refer to is only marked deleted: it disappears from the
+// list but existing references keep resolving.
+func (h *ExerciseHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(resourceID(r))
 	if err != nil {
-		http.Error(w, "Failed to fetch exercises", http.StatusInternalServerError)
+		http.Error(w, "Invalid exercise ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := h.editableExercise(w, r, id); !ok {
+		return
+	}
+
+	soft, err := h.exerciseRepo.Delete(r.Context(), id)
+	if err != nil {
+		log.Printf("Error deleting exercise: %v", err)
+		http.Error(w, "Failed to delete exercise", http.StatusInternalServerError)
+		return
+	}
+
+	if soft {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":           id,
+			"soft_deleted": true,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Merge folds a duplicate exercise into a canonical one: every workout,
+// template, program and record entry is moved over and the duplicate is
+// deleted.
+func (h *ExerciseHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		SourceID int `json:"source_id"`
+		TargetID int `json:"target_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	source, ok := h.editableExercise(w, r, input.SourceID)
+	if !ok {
+		return
+	}
+
+	target, ok := h.visibleExercise(w, r, strconv.Itoa(input.TargetID))
+	if !ok {
 		return
 	}
+
+	if err := source.CanMergeInto(target); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	moved, err := h.exerciseRepo.Merge(r.Context(), source.ID, target.ID)
+	if err != nil {
+		log.Printf("Error merging exercises: %v", err)
+		http.Error(w, "Failed to merge exercises", http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"exercise":      target,
+		"merged_id":     source.ID,
+		"moved_entries": moved,
+	})
+}
+
+// RequestPromotion puts one of the caller's custom exercises forward for
+// the global catalog.
+func (h *ExerciseHandler) RequestPromotion(w http.ResponseWriter, r *http.Request) {
+	exercise, ok := h.visibleExercise(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
 
+	h.updatePromotion(w, r, exercise, exercise.RequestPromotion)
+}
+
+// PendingPromotions lists the custom exercises waiting for review. Admin
+// only.
+func (h *ExerciseHandler) PendingPromotions(w http.ResponseWriter, r *http.Request) {
+	exercises, err := h.exerciseRepo.GetPendingPromotions(r.Context())
+	if err != nil {
+		log.Printf("Error fetching exercise promotions: %v", err)
+		http.Error(w, "Failed to fetch exercises", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(exercises)
 }
+
+// ApprovePromotion moves a pending exercise into the global catalog. Admin
+// only.
+func (h *ExerciseHandler) ApprovePromotion(w http.ResponseWriter, r *http.Request) {
+	exercise, ok := h.reviewedExercise(w, r)
+	if !ok {
+		return
+	}
+
+	h.updatePromotion(w, r, exercise, exercise.ApprovePromotion)
+}
+
+// RejectPromotion leaves a pending exercise with its owner. Admin only.
+func (h *ExerciseHandler) RejectPromotion(w http.ResponseWriter, r *http.Request) {
+	exercise, ok := h.reviewedExercise(w, r)
+	if !ok {
+		return
+	}
+
+	h.updatePromotion(w, r, exercise, exercise.RejectPromotion)
+}
+
+func (h *ExerciseHandler) updatePromotion(w http.ResponseWriter, r *http.Request, exercise *model.Exercise, apply func() error) {
+	if err := apply(); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	if err := h.exerciseRepo.UpdatePromotion(r.Context(), exercise); err != nil {
+		log.Printf("Error updating exercise promotion: %v", err)
+		http.Error(w, "Failed to update exercise", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(exercise)
+}
+
+func (h *ExerciseHandler) reviewedExercise(w http.ResponseWriter, r *http.Request) (*model.Exercise, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid exercise ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	exercise, err := h.exerciseRepo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Exercise not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return exercise, true
+}
+
+// editableExercise loads an exercise the caller may change. Exercises the
+// caller cannot see, or that are already deleted, are reported as not
+// found; catalog exercises are forbidden to non-admins.
+func (h *ExerciseHandler) editableExercise(w http.ResponseWriter, r *http.Request, id int) (*model.Exercise, bool) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetByID(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	exercise, err := h.exerciseRepo.GetByID(r.Context(), id)
+	if err != nil || !exercise.VisibleTo(userID) || exercise.DeletedAt != nil {
+		http.Error(w, "Exercise not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	if !exercise.EditableBy(user) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return nil, false
+	}
+
+	return exercise, true
+}
+
+// visibleExercise loads an exercise the caller can see. Other users' custom
+// exercises are reported as not found.
+func (h *ExerciseHandler) visibleExercise(w http.ResponseWriter, r *http.Request, idStr string) (*model.Exercise, bool) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid exercise ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	exercise, err := h.exerciseRepo.GetByID(r.Context(), id)
+	if err != nil || !exercise.VisibleTo(userID) {
+		http.Error(w, "Exercise not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return exercise, true
+}
+
+// isInvalidTaxonomy reports whether err is about an exercise's muscle
+// groups, equipment, movement pattern, laterality or measurement type.
+func isInvalidTaxonomy(err error) bool {
+	for _, target := range []error{
+		model.ErrUnknownMuscleGroup, model.ErrUnknownEquipment, model.ErrMuscleRoleConflict,
+		model.ErrInvalidMovementPattern, model.ErrInvalidLaterality, model.ErrInvalidMeasurementType,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMeasurements rejects workout entries that record values their
+// exercise's measurement type does not track, answering 400 itself.
+func checkMeasurements(w http.ResponseWriter, r *http.Request, exerciseRepo *repository.ExerciseRepository, entries []model.WorkoutExercise) bool {
+	ids := make([]int, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ExerciseID
+	}
+
+	types, err := exerciseRepo.MeasurementTypes(r.Context(), ids)
+	if err != nil {
+		log.Printf("Error checking exercise measurements: %v", err)
+		http.Error(w, "Failed to check exercises", http.StatusInternalServerError)
+		return false
+	}
+
+	for i := range entries {
+		if err := entries[i].CheckMeasurement(types[entries[i].ExerciseID]); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return false
+		}
+	}
+	return true
+}
+
+// checkExercisesVisible rejects a request that refers to exercises the
+// caller cannot see, answering 400 itself.
+func checkExercisesVisible(w http.ResponseWriter, r *http.Request, exerciseRepo *repository.ExerciseRepository, userID int, ids []int) bool {
+	missing, err := exerciseRepo.NotVisible(r.Context(), userID, ids)
+	if err != nil {
+		log.Printf("Error checking exercises: %v", err)
+		http.Error(w, "Failed to check exercises", http.StatusInternalServerError)
+		return false
+	}
+
+	if len(missing) > 0 {
+		http.Error(w, fmt.Sprintf("Unknown exercise ID %d", missing[0]), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
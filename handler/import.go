@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/importer"
+	"github.com/yeboahd24/workout-tracker/service"
+	"github.com/yeboahd24/workout-tracker/util"
+)
+
+// maxImportSize bounds the size of an uploaded export.
+const maxImportSize = 20 << 20
+
+type ImportHandler struct {
+	importService *service.ImportService
+}
+
+func NewImportHandler(importService *service.ImportService) *ImportHandler {
+	return &ImportHandler{importService: importService}
+}
+
+// Import reads a Strong or Hevy CSV export, either as the raw request body or
+// as the "file" field of a multipart form. Query parameters: format (strong or
+// hevy), tz (IANA zone the export's times are in, default UTC) and dry_run.
+func (h *ImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+
+	format, err := importer.ParseFormat(query.Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts := service.ImportOptions{Format: format, Location: time.UTC}
+	if tz := query.Get("tz"); tz != "" {
+		opts.Location, err = time.LoadLocation(tz)
+		if err != nil {
+			http.Error(w, "Invalid time zone", http.StatusBadRequest)
+			return
+		}
+	}
+	if dryRun := query.Get("dry_run"); dryRun != "" {
+		opts.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			http.Error(w, "Invalid dry_run value", http.StatusBadRequest)
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var body io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Missing file", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	result, err := h.importService.Import(r.Context(), userID, body, opts)
+	if err != nil {
+		if errors.Is(err, importer.ErrInvalidFile) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error importing workouts: %v", err)
+		http.Error(w, "Failed to import workouts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !result.DryRun {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+	"github.com/yeboahd24/workout-tracker/repository"
+	"github.com/yeboahd24/workout-tracker/service"
+)
+
+// keepAliveInterval is how often an idle stream gets a comment so proxies
+// do not close it.
+const keepAliveInterval = 15 * time.Second
+
+// LiveHandler serves live workout sessions as server-sent event streams.
+type LiveHandler struct {
+	workoutRepo  *repository.WorkoutRepository
+	exerciseRepo *repository.ExerciseRepository
+	recordRepo   *repository.RecordRepository
+	liveService  *service.LiveService
+}
+
+func NewLiveHandler(workoutRepo *repository.WorkoutRepository, exerciseRepo *repository.ExerciseRepository, recordRepo *repository.RecordRepository, liveService *service.LiveService) *LiveHandler {
+	return &LiveHandler{workoutRepo: workoutRepo, exerciseRepo: exerciseRepo, recordRepo: recordRepo, liveService: liveService}
+}
+
+// Start puts the workout in progress, unless it already is, and streams its
+// session.
+func (h *LiveHandler) Start(w http.ResponseWriter, r *http.Request) {
+	workout, ok := h.ownedWorkout(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	if err := h.liveService.Start(r.Context(), workout); err != nil {
+		if errors.Is(err, model.ErrInvalidTransition) {
+			http.Error(w, fmt.Sprintf("Cannot start a session for a %s workout", workout.Status), http.StatusConflict)
+			return
+		}
+		log.Printf("Error starting live session: %v", err)
+		http.Error(w, "Failed to start session", http.StatusInternalServerError)
+		return
+	}
+
+	h.stream(w, r, workout)
+}
+
+// Follow streams the session of a workout in progress, e.g. to a second
+// device.
+func (h *LiveHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	workout, ok := h.ownedWorkout(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	if workout.Status != model.StatusInProgress {
+		http.Error(w, model.ErrNotLive.Error(), http.StatusConflict)
+		return
+	}
+
+	h.stream(w, r, workout)
+}
+
+// CompleteSet logs a completed set of the session. The set is stored right
+// away and sent to every device following the session, which then get the
+// rest timer and the next set.
+func (h *LiveHandler) CompleteSet(w http.ResponseWriter, r *http.Request) {
+	workout, ok := h.ownedWorkout(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	var input struct {
+		model.WorkoutSet
+		RestSeconds *int `json:"rest_seconds"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if workout.Status != model.StatusInProgress {
+		http.Error(w, model.ErrNotLive.Error(), http.StatusConflict)
+		return
+	}
+	if input.RestSeconds != nil && *input.RestSeconds < 0 {
+		http.Error(w, model.ErrInvalidRest.Error(), http.StatusBadRequest)
+		return
+	}
+
+	set, err := workout.LogSet(input.WorkoutExerciseID, input.WorkoutSet)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var entry model.WorkoutExercise
+	for _, e := range workout.Exercises {
+		if e.ID == set.WorkoutExerciseID {
+			entry = e
+		}
+	}
+	if !checkMeasurements(w, r, h.exerciseRepo, []model.WorkoutExercise{entry}) {
+		return
+	}
+
+	if err := h.workoutRepo.LogSet(r.Context(), set); err != nil {
+		log.Printf("Error logging set: %v", err)
+		http.Error(w, "Failed to log set", http.StatusInternalServerError)
+		return
+	}
+
+	var records []model.RecordType
+	if _, err := h.recordRepo.DetectForWorkout(r.Context(), workout); err != nil {
+		log.Printf("Error detecting personal records: %v", err)
+	}
+	for _, e := range workout.Exercises {
+		if e.ID == set.WorkoutExerciseID {
+			records = e.Records
+		}
+	}
+
+	event := h.liveService.CompleteSet(workout, set, records, input.RestSeconds)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(event)
+}
+
+// stream writes the session's events until the session ends or the client
+// goes away.
+func (h *LiveHandler) stream(w http.ResponseWriter, r *http.Request, workout *model.Workout) {
+	events, stop := h.liveService.Follow(workout)
+	defer stop()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("Error encoding live event: %v", err)
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (h *LiveHandler) ownedWorkout(w http.ResponseWriter, r *http.Request, idStr string) (*model.Workout, bool) {
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid workout ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	return ownedWorkout(w, r, h.workoutRepo, id)
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+)
+
+// readMergePatch reads a JSON Merge Patch (RFC 7396) from the request body,
+// answering the request itself when it is not one. The patch must be an
+// object.
+func readMergePatch(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "application/merge-patch+json" && mediaType != "application/json") {
+			http.Error(w, "Content-Type must be application/merge-patch+json", http.StatusUnsupportedMediaType)
+			return nil, false
+		}
+	}
+
+	var patch map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		http.Error(w, "The patch must be a JSON object", http.StatusBadRequest)
+		return nil, false
+	}
+	return patch, true
+}
+
+// mergePatch applies a merge patch to the JSON form of current and decodes
+// the result into patched, which should start out empty so removed members
+// end up zero. Only the fields patched has can be changed.
+func mergePatch(current interface{}, patch map[string]interface{}, patched interface{}) error {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(mergeValue(target, patch))
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(patched)
+}
+
+// mergeValue is the MergePatch function of RFC 7396: objects are merged
+// member by member, a null member is removed and anything else replaces
+// the target.
+func mergeValue(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = mergeValue(targetObject[name], value)
+		}
+	}
+	return targetObject
+}
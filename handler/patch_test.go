@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+)
+
+// The cases are the examples of RFC 7396, Appendix A.
+func TestMergeValue(t *testing.T) {
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target+" + "+tt.patch, func(t *testing.T) {
+			var target, patch interface{}
+			if err := json.Unmarshal([]byte(tt.target), &target); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.Marshal(mergeValue(target, patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("mergeValue(%s, %s) = %s, want %s", tt.target, tt.patch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	scheduledFor := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	current := workoutPatch{Name: "Legs", Description: "Heavy day", ScheduledFor: scheduledFor}
+
+	tests := []struct {
+		name    string
+		patch   string
+		want    workoutPatch
+		wantErr bool
+	}{
+		{
+			name:  "empty patch keeps everything",
+			patch: `{}`,
+			want:  current,
+		},
+		{
+			name:  "member replaced",
+			patch: `{"name":"Squats"}`,
+			want:  workoutPatch{Name: "Squats", Description: "Heavy day", ScheduledFor: scheduledFor},
+		},
+		{
+			name:  "null clears a member",
+			patch: `{"description":null}`,
+			want:  workoutPatch{Name: "Legs", ScheduledFor: scheduledFor},
+		},
+		{
+			name:  "null clears scheduled_for",
+			patch: `{"scheduled_for":null}`,
+			want:  workoutPatch{Name: "Legs", Description: "Heavy day"},
+		},
+		{
+			name:    "unknown member",
+			patch:   `{"status":"completed"}`,
+			wantErr: true,
+		},
+		{
+			name:    "wrong type",
+			patch:   `{"name":1}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+
+			var got workoutPatch
+			err := mergePatch(current, patch, &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("mergePatch(%s) = %+v, want an error", tt.patch, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mergePatch(%s) error = %v", tt.patch, err)
+			}
+			if got.Name != tt.want.Name || got.Description != tt.want.Description || !got.ScheduledFor.Equal(tt.want.ScheduledFor) {
+				t.Errorf("mergePatch(%s) = %+v, want %+v", tt.patch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyExercisePatch(t *testing.T) {
+	type summary struct {
+		sets, reps int
+		weight     float64
+		setLog     int
+	}
+
+	tests := []struct {
+		name  string
+		patch string
+		want  summary
+	}{
+		{
+			name:  "set_log without a summary derives it",
+			patch: `{"set_log":[{"reps":8,"weight":60,"completed":true},{"reps":6,"weight":70,"completed":true}]}`,
+			want:  summary{sets: 2, reps: 6, weight: 70, setLog: 2},
+		},
+		{
+			name:  "warm-up sets do not count toward the derived summary",
+			patch: `{"set_log":[{"reps":10,"weight":40,"set_type":"warmup","completed":true},{"reps":5,"weight":80,"completed":true}]}`,
+			want:  summary{sets: 1, reps: 5, weight: 80, setLog: 2},
+		},
+		{
+			name:  "set_log with a summary keeps the summary given",
+			patch: `{"sets":4,"set_log":[{"reps":8,"weight":60,"completed":true}]}`,
+			want:  summary{sets: 4, reps: 5, weight: 100, setLog: 1},
+		},
+		{
+			name:  "summary without set_log",
+			patch: `{"weight":105}`,
+			want:  summary{sets: 3, reps: 5, weight: 105, setLog: 1},
+		},
+		{
+			name:  "other fields keep everything",
+			patch: `{"notes":"Felt easy"}`,
+			want:  summary{sets: 3, reps: 5, weight: 100, setLog: 1},
+		},
+		{
+			name:  "clearing set_log keeps the summary",
+			patch: `{"set_log":null}`,
+			want:  summary{sets: 3, reps: 5, weight: 100, setLog: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &model.WorkoutExercise{
+				ID:         1,
+				ExerciseID: 3,
+				Position:   1,
+				Sets:       3,
+				Reps:       5,
+				Weight:     100,
+				SetLog: []model.WorkoutSet{
+					{SetNumber: 1, Reps: 5, Weight: 100, SetType: model.SetTypeWorking, Completed: true},
+				},
+			}
+			current := workoutExercisePatch{
+				ExerciseID: entry.ExerciseID,
+				Position:   entry.Position,
+				Sets:       entry.Sets,
+				Reps:       entry.Reps,
+				Weight:     entry.Weight,
+				SetLog:     entry.SetLog,
+			}
+
+			var patch map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+			var fields workoutExercisePatch
+			if err := mergePatch(current, patch, &fields); err != nil {
+				t.Fatalf("mergePatch(%s) error = %v", tt.patch, err)
+			}
+
+			if err := applyExercisePatch(entry, patch, fields); err != nil {
+				t.Fatalf("applyExercisePatch(%s) error = %v", tt.patch, err)
+			}
+
+			got := summary{sets: entry.Sets, reps: entry.Reps, weight: entry.Weight, setLog: len(entry.SetLog)}
+			if got != tt.want {
+				t.Errorf("applyExercisePatch(%s) = %+v, want %+v", tt.patch, got, tt.want)
+			}
+		})
+	}
+}
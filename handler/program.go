@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+	"github.com/yeboahd24/workout-tracker/repository"
+	"github.com/yeboahd24/workout-tracker/service"
+	"github.com/yeboahd24/workout-tracker/util"
+)
+
+type ProgramHandler struct {
+	programRepo    *repository.ProgramRepository
+	exerciseRepo   *repository.ExerciseRepository
+	programService *service.ProgramService
+}
+
+func NewProgramHandler(programRepo *repository.ProgramRepository, exerciseRepo *repository.ExerciseRepository, programService *service.ProgramService) *ProgramHandler {
+	return &ProgramHandler{programRepo: programRepo, exerciseRepo: exerciseRepo, programService: programService}
+}
+
+func (h *ProgramHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input struct {
+		Name        string             `json:"name"`
+		Description string             `json:"description"`
+		Weeks       int                `json:"weeks"`
+		Days        []model.ProgramDay `json:"days"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	program := model.NewProgram(userID, input.Name, input.Description, input.Weeks)
+	program.Days = input.Days
+	if err := program.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !checkExercisesVisible(w, r, h.exerciseRepo, userID, program.ExerciseIDs()) {
+		return
+	}
+
+	if err := h.programRepo.Create(r.Context(), program); err != nil {
+		log.Printf("Error creating program: %v", err)
+		http.Error(w, "Failed to create program", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(program)
+}
+
+func (h *ProgramHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	program, ok := h.ownedProgram(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(program)
+}
+
+func (h *ProgramHandler) GetByUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	programs, err := h.programRepo.GetByUserID(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to fetch programs", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(programs)
+}
+
+func (h *ProgramHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	program, ok := h.ownedProgram(w, r, r.URL.Query().Get("id"))
+	if !ok {
+		return
+	}
+
+	if err := h.programRepo.Delete(r.Context(), program.ID); err != nil {
+		log.Printf("Error deleting program: %v", err)
+		http.Error(w, "Failed to delete program", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Enroll schedules the program's workouts on the caller's calendar.
+func (h *ProgramHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	program, ok := h.ownedProgram(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	var input struct {
+		StartDate time.Time `json:"start_date"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if input.StartDate.IsZero() {
+		http.Error(w, "start_date is required", http.StatusBadRequest)
+		return
+	}
+
+	enrollment, err := h.programService.Enroll(r.Context(), program, program.UserID, input.StartDate)
+	if err != nil {
+		log.Printf("Error enrolling in program: %v", err)
+		http.Error(w, "Failed to enroll in program", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+func (h *ProgramHandler) GetEnrollments(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	enrollments, err := h.programRepo.GetEnrollmentsByUserID(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to fetch enrollments", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(enrollments)
+}
+
+// ownedProgram loads the program with the given ID and checks that it
+// belongs to the caller, writing the error response when it does not.
+func (h *ProgramHandler) ownedProgram(w http.ResponseWriter, r *http.Request, idStr string) (*model.Program, bool) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid program ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	program, err := h.programRepo.GetByID(r.Context(), id)
+	if err != nil {
+		log.Printf("Error fetching program: %v", err)
+		http.Error(w, "Failed to fetch program", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if program == nil {
+		http.Error(w, "Program not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	if program.UserID != userID {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	return program, true
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+)
+
+// resourceID reads the ID of the resource a request is about from the path,
+// falling back to the id query parameter the deprecated routes take.
+func resourceID(r *http.Request) string {
+	if id := r.PathValue("id"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("id")
+}
+
+// parseDateRange reads the start_date and end_date query parameters
+// (YYYY-MM-DD). A missing end_date means today and a missing start_date
+// means defaultDays before the end. The end is extended to the end of its day.
+func parseDateRange(r *http.Request, defaultDays int) (time.Time, time.Time, error) {
+	query := r.URL.Query()
+
+	end := time.Now().UTC()
+	if s := query.Get("end_date"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid end_date, expected YYYY-MM-DD")
+		}
+		end = parsed
+	}
+	end = time.Date(end.Year(), end.Month(), end.Day(), 23, 59, 59, 0, time.UTC)
+
+	start := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -defaultDays)
+	if s := query.Get("start_date"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid start_date, expected YYYY-MM-DD")
+		}
+		start = parsed
+	}
+
+	if start.After(end) {
+		return time.Time{}, time.Time{}, model.ErrInvalidDateRange
+	}
+
+	return start, end, nil
+}
+
+// parseReportQuery reads the report parameters: the date range (last 30
+// days by default), status (completed by default, "all" for any) and an
+// optional group_by of day, week or month.
+func parseReportQuery(r *http.Request) (model.ReportQuery, error) {
+	start, end, err := parseDateRange(r, 30)
+	if err != nil {
+		return model.ReportQuery{}, err
+	}
+
+	// Reports only count completed sessions unless asked otherwise
+	statusParam := r.URL.Query().Get("status")
+	switch statusParam {
+	case "":
+		statusParam = string(model.StatusCompleted)
+	case "all":
+		statusParam = ""
+	}
+	status, err := model.ParseWorkoutStatus(statusParam)
+	if err != nil {
+		return model.ReportQuery{}, err
+	}
+
+	groupBy, err := model.ParseInterval(r.URL.Query().Get("group_by"), "")
+	if err != nil {
+		return model.ReportQuery{}, errors.New("group_by must be one of day, week, month")
+	}
+
+	return model.ReportQuery{Start: start, End: end, Status: status, GroupBy: groupBy}, nil
+}
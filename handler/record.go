@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/yeboahd24/workout-tracker/repository"
+	"github.com/yeboahd24/workout-tracker/util"
+)
+
+type RecordHandler struct {
+	recordRepo *repository.RecordRepository
+}
+
+func NewRecordHandler(recordRepo *repository.RecordRepository) *RecordHandler {
+	return &RecordHandler{recordRepo: recordRepo}
+}
+
+// GetByUser lists the caller's personal records per exercise. An optional
+// exercise_id query parameter narrows it to one exercise.
+func (h *RecordHandler) GetByUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var exerciseID int
+	if idStr := r.URL.Query().Get("exercise_id"); idStr != "" {
+		exerciseID, err = strconv.Atoi(idStr)
+		if err != nil {
+			http.Error(w, "Invalid exercise ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	records, err := h.recordRepo.GetByUserID(r.Context(), userID, exerciseID)
+	if err != nil {
+		log.Printf("Error fetching records: %v", err)
+		http.Error(w, "Failed to fetch records", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+	"github.com/yeboahd24/workout-tracker/repository"
+	"github.com/yeboahd24/workout-tracker/service"
+	"github.com/yeboahd24/workout-tracker/util"
+)
+
+// scheduleInput holds the fields of a schedule a request may set. On update
+// fields left out keep their value.
+type scheduleInput struct {
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	TemplateID  *int      `json:"template_id"`
+	TimeZone    *string   `json:"timezone"`
+	Start       *string   `json:"start"`
+	RRule       *string   `json:"rrule"`
+	Exceptions  *[]string `json:"exceptions"`
+}
+
+type ScheduleHandler struct {
+	scheduleRepo    *repository.ScheduleRepository
+	templateRepo    *repository.TemplateRepository
+	scheduleService *service.ScheduleService
+}
+
+func NewScheduleHandler(scheduleRepo *repository.ScheduleRepository, templateRepo *repository.TemplateRepository, scheduleService *service.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{scheduleRepo: scheduleRepo, templateRepo: templateRepo, scheduleService: scheduleService}
+}
+
+func (h *ScheduleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input scheduleInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if input.Start == nil || input.RRule == nil {
+		http.Error(w, "start and rrule are required", http.StatusBadRequest)
+		return
+	}
+
+	schedule := &model.Schedule{UserID: userID, TimeZone: "UTC", CreatedAt: time.Now(), Exceptions: []string{}}
+	if !h.applyInput(w, r, schedule, input) {
+		return
+	}
+
+	if err := h.scheduleService.Create(r.Context(), schedule); err != nil {
+		log.Printf("Error creating schedule: %v", err)
+		http.Error(w, "Failed to create schedule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(schedule)
+}
+
+func (h *ScheduleHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.ownedSchedule(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedule)
+}
+
+func (h *ScheduleHandler) GetByUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	schedules, err := h.scheduleRepo.GetByUserID(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error fetching schedules: %v", err)
+		http.Error(w, "Failed to fetch schedules", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedules)
+}
+
+// Update changes one occurrence (scope "this") or the series from an
+// occurrence on (scope "future", the default). A "future" edit from a later
+// occurrence splits the series in two so earlier workouts keep the old
+// rule; without an occurrence it changes the whole series and replaces the
+// upcoming workouts.
+func (h *ScheduleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		scheduleInput
+		ID           int        `json:"id"`
+		Scope        string     `json:"scope"`
+		Occurrence   *time.Time `json:"occurrence"`
+		ScheduledFor *time.Time `json:"scheduled_for"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The deprecated route takes the ID in the body
+	idStr := r.PathValue("id")
+	if idStr == "" {
+		idStr = strconv.Itoa(input.ID)
+	}
+
+	schedule, ok := h.ownedSchedule(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	switch input.Scope {
+	case "this":
+		if input.Occurrence == nil {
+			http.Error(w, "occurrence is required to edit a single occurrence", http.StatusBadRequest)
+			return
+		}
+
+		workout, err := h.scheduleService.EditOccurrence(r.Context(), schedule, *input.Occurrence, func(workout *model.Workout) {
+			if input.Name != nil {
+				workout.Name = *input.Name
+			}
+			if input.Description != nil {
+				workout.Description = *input.Description
+			}
+			if input.ScheduledFor != nil {
+				workout.ScheduledFor = *input.ScheduledFor
+			}
+		})
+		if errors.Is(err, service.ErrNoOccurrence) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Printf("Error updating schedule occurrence: %v", err)
+			http.Error(w, "Failed to update schedule", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(workout)
+		return
+
+	case "", "future":
+		if input.Occurrence != nil && input.Occurrence.After(schedule.StartsAt) {
+			next, err := schedule.Split(*input.Occurrence)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if input.Start == nil {
+				start := next.StartsAt.Format("2006-01-02T15:04:05")
+				input.Start = &start
+			}
+			if !h.applyInput(w, r, next, input.scheduleInput) {
+				return
+			}
+
+			if err := h.scheduleService.SplitAt(r.Context(), schedule, next, *input.Occurrence); err != nil {
+				log.Printf("Error splitting schedule: %v", err)
+				http.Error(w, "Failed to update schedule", http.StatusInternalServerError)
+				return
+			}
+			schedule = next
+		} else {
+			if !h.applyInput(w, r, schedule, input.scheduleInput) {
+				return
+			}
+
+			if err := h.scheduleService.Reschedule(r.Context(), schedule); err != nil {
+				log.Printf("Error rescheduling: %v", err)
+				http.Error(w, "Failed to update schedule", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(schedule)
+		return
+
+	default:
+		http.Error(w, "scope must be one of this, future", http.StatusBadRequest)
+	}
+}
+
+// AddException leaves a date (YYYY-MM-DD, in the schedule's time zone) out
+// of the series.
+func (h *ScheduleHandler) AddException(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.ownedSchedule(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Date string `json:"date"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.scheduleService.AddException(r.Context(), schedule, input.Date); err != nil {
+		if errors.Is(err, model.ErrInvalidSchedule) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error adding schedule exception: %v", err)
+		http.Error(w, "Failed to update schedule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedule)
+}
+
+func (h *ScheduleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.ownedSchedule(w, r, resourceID(r))
+	if !ok {
+		return
+	}
+
+	if err := h.scheduleService.Delete(r.Context(), schedule); err != nil {
+		log.Printf("Error deleting schedule: %v", err)
+		http.Error(w, "Failed to delete schedule", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyInput sets the given fields on the schedule and validates it. Start
+// is a local time (e.g. 2026-01-05T07:00) in the schedule's time zone;
+// changing only the time zone keeps the same wall-clock start.
+func (h *ScheduleHandler) applyInput(w http.ResponseWriter, r *http.Request, schedule *model.Schedule, input scheduleInput) bool {
+	wallClock := schedule.StartsAt.Format("2006-01-02T15:04:05")
+	if input.Start != nil {
+		wallClock = *input.Start
+	}
+	if input.Name != nil {
+		schedule.Name = *input.Name
+	}
+	if input.Description != nil {
+		schedule.Description = *input.Description
+	}
+	if input.TimeZone != nil {
+		schedule.TimeZone = *input.TimeZone
+	}
+	if input.RRule != nil {
+		schedule.RRule = *input.RRule
+	}
+	if input.Exceptions != nil {
+		schedule.Exceptions = *input.Exceptions
+	}
+
+	if input.TemplateID != nil {
+		template, err := h.templateRepo.GetByID(r.Context(), *input.TemplateID)
+		if err != nil {
+			log.Printf("Error fetching template: %v", err)
+			http.Error(w, "Failed to fetch template", http.StatusInternalServerError)
+			return false
+		}
+		if template == nil || template.UserID != schedule.UserID {
+			http.Error(w, "Template not found", http.StatusBadRequest)
+			return false
+		}
+		schedule.TemplateID = input.TemplateID
+	}
+
+	loc, err := time.LoadLocation(schedule.TimeZone)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Unknown time zone %q", schedule.TimeZone), http.StatusBadRequest)
+		return false
+	}
+	startsAt, err := parseLocalTime(wallClock, loc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	schedule.StartsAt = startsAt
+	schedule.UpdatedAt = time.Now()
+
+	if err := schedule.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func parseLocalTime(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid start %q, expected YYYY-MM-DDTHH:MM", value)
+}
+
+func (h *ScheduleHandler) ownedSchedule(w http.ResponseWriter, r *http.Request, idStr string) (*model.Schedule, bool) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid schedule ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	schedule, err := h.scheduleRepo.GetByID(r.Context(), id)
+	if err != nil {
+		log.Printf("Error fetching schedule: %v", err)
+		http.Error(w, "Failed to fetch schedule", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	// Someone else's schedule is not found either, so IDs cannot be probed
+	if schedule == nil || schedule.UserID != userID {
+		http.Error(w, "Schedule not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return schedule, true
+}
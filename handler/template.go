@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+	"github.com/yeboahd24/workout-tracker/repository"
+	"github.com/yeboahd24/workout-tracker/util"
+)
+
+type templateExerciseInput struct {
+	ExerciseID   int     `json:"exercise_id"`
+	TargetSets   int     `json:"target_sets"`
+	TargetReps   int     `json:"target_reps"`
+	TargetWeight float64 `json:"target_weight"`
+	Notes        string  `json:"notes"`
+}
+
+type TemplateHandler struct {
+	templateRepo *repository.TemplateRepository
+	workoutRepo  *repository.WorkoutRepository
+	exerciseRepo *repository.ExerciseRepository
+}
+
+func NewTemplateHandler(templateRepo *repository.TemplateRepository, workoutRepo *repository.WorkoutRepository, exerciseRepo *repository.ExerciseRepository) *TemplateHandler {
+	return &TemplateHandler{templateRepo: templateRepo, workoutRepo: workoutRepo, exerciseRepo: exerciseRepo}
+}
+
+func (h *TemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input struct {
+		Name        string                  `json:"name"`
+		Description string                  `json:"description"`
+		Exercises   []templateExerciseInput `json:"exercises"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	template := model.NewWorkoutTemplate(userID, input.Name, input.Description)
+	for _, e := range input.Exercises {
+		template.AddExercise(e.ExerciseID, e.TargetSets, e.TargetReps, e.TargetWeight, e.Notes)
+	}
+
+	if !checkExercisesVisible(w, r, h.exerciseRepo, userID, template.ExerciseIDs()) {
+		return
+	}
+
+	if err := h.templateRepo.Create(r.Context(), template); err != nil {
+		log.Printf("Error creating template: %v", err)
+		http.Error(w, "Failed to create template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
+
+// CreateFromWorkout saves an existing workout's exercises as a new template.
+func (h *TemplateHandler) CreateFromWorkout(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		WorkoutID int    `json:"workout_id"`
+		Name      string `json:"name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	workout, ok := ownedWorkout(w, r, h.workoutRepo, input.WorkoutID)
+	if !ok {
+		return
+	}
+
+	template := model.NewTemplateFromWorkout(workout, input.Name)
+	if err := h.templateRepo.Create(r.Context(), template); err != nil {
+		log.Printf("Error creating template: %v", err)
+		http.Error(w, "Failed to create template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
+
+func (h *TemplateHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	template, ok := h.ownedTemplate(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+func (h *TemplateHandler) GetByUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	templates, err := h.templateRepo.GetByUserID(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to fetch templates", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+func (h *TemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		ID          int                     `json:"id"`
+		Name        string                  `json:"name"`
+		Description string                  `json:"description"`
+		Exercises   []templateExerciseInput `json:"exercises"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	template, ok := h.ownedTemplate(w, r, strconv.Itoa(input.ID))
+	if !ok {
+		return
+	}
+
+	template.Name = input.Name
+	template.Description = input.Description
+	template.UpdatedAt = time.Now()
+	template.Exercises = make([]model.TemplateExercise, 0, len(input.Exercises))
+	for _, e := range input.Exercises {
+		template.AddExercise(e.ExerciseID, e.TargetSets, e.TargetReps, e.TargetWeight, e.Notes)
+	}
+
+	if !checkExercisesVisible(w, r, h.exerciseRepo, template.UserID, template.ExerciseIDs()) {
+		return
+	}
+
+	if err := h.templateRepo.Update(r.Context(), template); err != nil {
+		log.Printf("Error updating template: %v", err)
+		http.Error(w, "Failed to update template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+func (h *TemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	template, ok := h.ownedTemplate(w, r, r.URL.Query().Get("id"))
+	if !ok {
+		return
+	}
+
+	if err := h.templateRepo.Delete(r.Context(), template.ID); err != nil {
+		log.Printf("Error deleting template: %v", err)
+		http.Error(w, "Failed to delete template", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Instantiate creates a concrete workout from the template for the given date.
+func (h *TemplateHandler) Instantiate(w http.ResponseWriter, r *http.Request) {
+	template, ok := h.ownedTemplate(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	var input struct {
+		ScheduledFor time.Time `json:"scheduled_for"`
+		Name         string    `json:"name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if input.ScheduledFor.IsZero() {
+		http.Error(w, "scheduled_for is required", http.StatusBadRequest)
+		return
+	}
+
+	workout := template.Instantiate(input.ScheduledFor)
+	if input.Name != "" {
+		workout.Name = input.Name
+	}
+
+	if err := h.workoutRepo.Create(r.Context(), workout); err != nil {
+		log.Printf("Error instantiating template: %v", err)
+		http.Error(w, "Failed to create workout", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(workout)
+}
+
+// ownedTemplate loads the template with the given ID and checks that it
+// belongs to the caller, writing the error response when it does not.
+func (h *TemplateHandler) ownedTemplate(w http.ResponseWriter, r *http.Request, idStr string) (*model.WorkoutTemplate, bool) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	template, err := h.templateRepo.GetByID(r.Context(), id)
+	if err != nil {
+		log.Printf("Error fetching template: %v", err)
+		http.Error(w, "Failed to fetch template", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if template == nil {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	if template.UserID != userID {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	return template, true
+}
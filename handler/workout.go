@@ -2,22 +2,65 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/yeboahd24/workout-tracker/export"
 	"github.com/yeboahd24/workout-tracker/model"
 	"github.com/yeboahd24/workout-tracker/repository"
+	"github.com/yeboahd24/workout-tracker/service"
 	"github.com/yeboahd24/workout-tracker/util"
 )
 
+type workoutExerciseInput struct {
+	ID         int                `json:"id"`
+	ExerciseID int                `json:"exercise_id"`
+	Group      string             `json:"group"`
+	Sets       int                `json:"sets"`
+	Reps       int                `json:"reps"`
+	Weight     float64            `json:"weight"`
+	Distance   float64            `json:"distance"`
+	Duration   int                `json:"duration"`
+	Notes      string             `json:"notes"`
+	SetLog     []model.WorkoutSet `json:"set_log"`
+}
+
+// workoutPatch holds the workout fields a merge patch can change.
+type workoutPatch struct {
+	Name         string                `json:"name"`
+	Description  string                `json:"description"`
+	ScheduledFor time.Time             `json:"scheduled_for"`
+	Groups       []model.ExerciseGroup `json:"groups"`
+}
+
+// workoutExercisePatch holds the exercise entry fields a merge patch can
+// change.
+type workoutExercisePatch struct {
+	ExerciseID int                `json:"exercise_id"`
+	Position   int                `json:"position"`
+	Group      string             `json:"group"`
+	Sets       int                `json:"sets"`
+	Reps       int                `json:"reps"`
+	Weight     float64            `json:"weight"`
+	Distance   float64            `json:"distance"`
+	Duration   int                `json:"duration"`
+	Notes      string             `json:"notes"`
+	SetLog     []model.WorkoutSet `json:"set_log"`
+}
+
 type WorkoutHandler struct {
-	workoutRepo *repository.WorkoutRepository
+	workoutRepo    *repository.WorkoutRepository
+	exerciseRepo   *repository.ExerciseRepository
+	recordRepo     *repository.RecordRepository
+	programService *service.ProgramService
+	liveService    *service.LiveService
 }
 
-func NewWorkoutHandler(workoutRepo *repository.WorkoutRepository) *WorkoutHandler {
-	return &WorkoutHandler{workoutRepo: workoutRepo}
+func NewWorkoutHandler(workoutRepo *repository.WorkoutRepository, exerciseRepo *repository.ExerciseRepository, recordRepo *repository.RecordRepository, programService *service.ProgramService, liveService *service.LiveService) *WorkoutHandler {
+	return &WorkoutHandler{workoutRepo: workoutRepo, exerciseRepo: exerciseRepo, recordRepo: recordRepo, programService: programService, liveService: liveService}
 }
 
 func (h *WorkoutHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -28,16 +71,11 @@ func (h *WorkoutHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var input struct {
-		Name         string    `json:"name"`
-		Description  string    `json:"description"`
-		ScheduledFor time.Time `json:"scheduled_for"`
-		Exercises    []struct {
-			ExerciseID int     `json:"exercise_id"`
-			Sets       int     `json:"sets"`
-			Reps       int     `json:"reps"`
-			Weight     float64 `json:"weight"`
-			Notes      string  `json:"notes"`
-		} `json:"exercises"`
+		Name         string                 `json:"name"`
+		Description  string                 `json:"description"`
+		ScheduledFor time.Time              `json:"scheduled_for"`
+		Exercises    []workoutExerciseInput `json:"exercises"`
+		Groups       []model.ExerciseGroup  `json:"groups"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
@@ -47,30 +85,47 @@ func (h *WorkoutHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	workout := model.NewWorkout(userID, input.Name, input.Description, input.ScheduledFor)
 	for _, e := range input.Exercises {
-		workout.AddExercise(e.ExerciseID, e.Sets, e.Reps, e.Weight, e.Notes)
+		exercise := workout.AddExercise(e.ExerciseID, e.Sets, e.Reps, e.Weight, e.Notes)
+		exercise.Group = e.Group
+		exercise.Distance = e.Distance
+		exercise.Duration = e.Duration
+		if err := exercise.SetSetLog(e.SetLog); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := workout.SetGroups(input.Groups); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !checkExercisesVisible(w, r, h.exerciseRepo, userID, workout.ExerciseIDs()) {
+		return
+	}
+	if !checkMeasurements(w, r, h.exerciseRepo, workout.Exercises) {
+		return
 	}
 
 	if err := h.workoutRepo.Create(r.Context(), workout); err != nil {
 		http.Error(w, "Failed to create workout", http.StatusInternalServerError)
 		return
 	}
+	h.detectRecords(r, workout)
 
-	w.WriteHeader(http.StatusCreated)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(workout)
 }
 
 func (h *WorkoutHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Query().Get("id")
-	id, err := strconv.Atoi(idStr)
+	id, err := strconv.Atoi(resourceID(r))
 	if err != nil {
 		http.Error(w, "Invalid workout ID", http.StatusBadRequest)
 		return
 	}
 
-	workout, err := h.workoutRepo.GetByID(r.Context(), id)
-	if err != nil {
-		http.Error(w, "Workout not found", http.StatusNotFound)
+	workout, ok := ownedWorkout(w, r, h.workoutRepo, id)
+	if !ok {
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -85,7 +140,13 @@ func (h *WorkoutHandler) GetByUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	workouts, err := h.workoutRepo.GetByUserID(r.Context(), userID)
+	status, err := model.ParseWorkoutStatus(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	workouts, err := h.workoutRepo.GetByUserID(r.Context(), userID, status)
 	if err != nil {
 		http.Error(w, "Failed to fetch workouts", http.StatusInternalServerError)
 		return
@@ -97,24 +158,13 @@ func (h *WorkoutHandler) GetByUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *WorkoutHandler) Update(w http.ResponseWriter, r *http.Request) {
-	userID, err := util.GetUserIDFromContext(r.Context())
-	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
 	var input struct {
-		ID           int       `json:"id"`
-		Name         string    `json:"name"`
-		Description  string    `json:"description"`
-		ScheduledFor time.Time `json:"scheduled_for"`
-		Exercises    []struct {
-			ExerciseID int     `json:"exercise_id"`
-			Sets       int     `json:"sets"`
-			Reps       int     `json:"reps"`
-			Weight     float64 `json:"weight"`
-			Notes      string  `json:"notes"`
-		} `json:"exercises"`
+		ID           int                    `json:"id"`
+		Name         string                 `json:"name"`
+		Description  string                 `json:"description"`
+		ScheduledFor time.Time              `json:"scheduled_for"`
+		Exercises    []workoutExerciseInput `json:"exercises"`
+		Groups       []model.ExerciseGroup  `json:"groups"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
@@ -122,62 +172,328 @@ func (h *WorkoutHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	workout, err := h.workoutRepo.GetByID(r.Context(), input.ID)
-	if err != nil {
-		http.Error(w, "Workout not found", http.StatusNotFound)
-		return
+	// The deprecated route takes the ID in the body
+	if idStr := r.PathValue("id"); idStr != "" {
+		var err error
+		if input.ID, err = strconv.Atoi(idStr); err != nil {
+			http.Error(w, "Invalid workout ID", http.StatusBadRequest)
+			return
+		}
 	}
 
-	if workout.UserID != userID {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	workout, ok := ownedWorkout(w, r, h.workoutRepo, input.ID)
+	if !ok {
 		return
 	}
 
+	before := workout.ExerciseIDs()
+	entries := make(map[int]bool, len(workout.Exercises))
+	for _, e := range workout.Exercises {
+		entries[e.ID] = true
+	}
+
 	workout.Name = input.Name
 	workout.Description = input.Description
 	workout.ScheduledFor = input.ScheduledFor
+	workout.UpdatedAt = time.Now()
+	workout.Groups = input.Groups
 	workout.Exercises = make([]model.WorkoutExercise, len(input.Exercises))
 	for i, e := range input.Exercises {
+		// Entries sent with their ID are kept, along with the IDs of
+		// their sets; the others are replaced.
+		if e.ID != 0 {
+			if !entries[e.ID] {
+				http.Error(w, fmt.Sprintf("exercises[%d].id %d is not an exercise of this workout", i, e.ID), http.StatusBadRequest)
+				return
+			}
+			delete(entries, e.ID)
+		}
 		workout.Exercises[i] = model.WorkoutExercise{
+			ID:         e.ID,
 			ExerciseID: e.ExerciseID,
+			Group:      e.Group,
 			Sets:       e.Sets,
 			Reps:       e.Reps,
 			Weight:     e.Weight,
+			Distance:   e.Distance,
+			Duration:   e.Duration,
 			Notes:      e.Notes,
 		}
+		if err := workout.Exercises[i].SetSetLog(e.SetLog); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
 
-	if err := h.workoutRepo.Update(r.Context(), workout); err != nil {
-		http.Error(w, "Failed to update workout", http.StatusInternalServerError)
+	h.save(w, r, workout, before, http.StatusOK)
+}
+
+// Patch changes the workout fields given in a JSON Merge Patch (RFC 7396):
+// name, description, scheduled_for and groups. Fields left out keep their
+// value and null clears them, except scheduled_for, which a workout always
+// has. Exercises are changed one at a time through the
+// /workouts/{id}/exercises routes.
+func (h *WorkoutHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid workout ID", http.StatusBadRequest)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
 
-	json.NewEncoder(w).Encode(workout)
+	workout, ok := ownedWorkout(w, r, h.workoutRepo, id)
+	if !ok {
+		return
+	}
+
+	patch, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	current := workoutPatch{
+		Name:         workout.Name,
+		Description:  workout.Description,
+		ScheduledFor: workout.ScheduledFor,
+		Groups:       workout.Groups,
+	}
+	var fields workoutPatch
+	if err := mergePatch(current, patch, &fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if fields.ScheduledFor.IsZero() {
+		http.Error(w, "scheduled_for cannot be cleared", http.StatusBadRequest)
+		return
+	}
+
+	before := workout.ExerciseIDs()
+	workout.Name = fields.Name
+	workout.Description = fields.Description
+	workout.ScheduledFor = fields.ScheduledFor
+	workout.Groups = fields.Groups
+	workout.UpdatedAt = time.Now()
+
+	h.save(w, r, workout, before, http.StatusOK)
 }
 
-func (h *WorkoutHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	userID, err := util.GetUserIDFromContext(r.Context())
+// AddExercise adds an exercise entry to a workout, last unless a position is
+// given. The other entries are left as they are.
+func (h *WorkoutHandler) AddExercise(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		http.Error(w, "Invalid workout ID", http.StatusBadRequest)
+		return
+	}
+
+	workout, ok := ownedWorkout(w, r, h.workoutRepo, id)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		workoutExerciseInput
+		Position int `json:"position"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entry := model.WorkoutExercise{
+		ExerciseID: input.ExerciseID,
+		Group:      input.Group,
+		Sets:       input.Sets,
+		Reps:       input.Reps,
+		Weight:     input.Weight,
+		Distance:   input.Distance,
+		Duration:   input.Duration,
+		Notes:      input.Notes,
+	}
+	if err := entry.SetSetLog(input.SetLog); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before := workout.ExerciseIDs()
+	workout.InsertExercise(input.Position, entry)
+	workout.UpdatedAt = time.Now()
+
+	h.save(w, r, workout, before, http.StatusCreated)
+}
+
+// PatchExercise changes the fields of one exercise entry given in a JSON
+// Merge Patch (RFC 7396). A set_log replaces the entry's logged sets; sets
+// whose number is kept keep their IDs. A position moves the entry.
+func (h *WorkoutHandler) PatchExercise(w http.ResponseWriter, r *http.Request) {
+	workout, entry, ok := h.ownedEntry(w, r)
+	if !ok {
+		return
+	}
+
+	patch, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	current := workoutExercisePatch{
+		ExerciseID: entry.ExerciseID,
+		Position:   entry.Position,
+		Group:      entry.Group,
+		Sets:       entry.Sets,
+		Reps:       entry.Reps,
+		Weight:     entry.Weight,
+		Distance:   entry.Distance,
+		Duration:   entry.Duration,
+		Notes:      entry.Notes,
+		SetLog:     entry.SetLog,
+	}
+	var fields workoutExercisePatch
+	if err := mergePatch(current, patch, &fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before := workout.ExerciseIDs()
+	if err := applyExercisePatch(entry, patch, fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if fields.Position != entry.Position {
+		if err := workout.MoveExercise(entry.ID, fields.Position); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	workout.UpdatedAt = time.Now()
+
+	h.save(w, r, workout, before, http.StatusOK)
+}
+
+// applyExercisePatch sets the patched fields on the entry. A patch that
+// replaces the set_log without giving a new sets/reps/weight summary gets
+// the summary derived from the new sets, rather than keeping the old one.
+// Clearing the set_log with null keeps the summary.
+func applyExercisePatch(entry *model.WorkoutExercise, patch map[string]interface{}, fields workoutExercisePatch) error {
+	setLogPatched := patch["set_log"] != nil
+	summaryPatched := false
+	for _, name := range []string{"sets", "reps", "weight", "distance", "duration"} {
+		if _, ok := patch[name]; ok {
+			summaryPatched = true
+		}
+	}
+	if setLogPatched && !summaryPatched {
+		fields.Sets, fields.Reps, fields.Weight, fields.Distance, fields.Duration = 0, 0, 0, 0, 0
+	}
+
+	entry.ExerciseID = fields.ExerciseID
+	entry.Group = fields.Group
+	entry.Sets = fields.Sets
+	entry.Reps = fields.Reps
+	entry.Weight = fields.Weight
+	entry.Distance = fields.Distance
+	entry.Duration = fields.Duration
+	entry.Notes = fields.Notes
+	return entry.SetSetLog(fields.SetLog)
+}
+
+// RemoveExercise deletes one exercise entry, with its logged sets, from a
+// workout.
+func (h *WorkoutHandler) RemoveExercise(w http.ResponseWriter, r *http.Request) {
+	workout, entry, ok := h.ownedEntry(w, r)
+	if !ok {
 		return
 	}
 
-	idStr := r.URL.Query().Get("id")
-	id, err := strconv.Atoi(idStr)
+	before := workout.ExerciseIDs()
+	if err := workout.RemoveExercise(entry.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	workout.UpdatedAt = time.Now()
+
+	h.save(w, r, workout, before, http.StatusOK)
+}
+
+// ownedEntry fetches a workout of the caller and the exercise entry named by
+// the entry_id path parameter.
+func (h *WorkoutHandler) ownedEntry(w http.ResponseWriter, r *http.Request) (*model.Workout, *model.WorkoutExercise, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
 		http.Error(w, "Invalid workout ID", http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	entryID, err := strconv.Atoi(r.PathValue("entry_id"))
+	if err != nil {
+		http.Error(w, "Invalid workout exercise ID", http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	workout, ok := ownedWorkout(w, r, h.workoutRepo, id)
+	if !ok {
+		return nil, nil, false
+	}
+
+	for i := range workout.Exercises {
+		if workout.Exercises[i].ID == entryID {
+			return workout, &workout.Exercises[i], true
+		}
+	}
+
+	http.Error(w, "Workout exercise not found", http.StatusNotFound)
+	return nil, nil, false
+}
+
+// save checks a changed workout and stores it, answering with the workout.
+// Exercises the workout used before may since have been deleted; only newly
+// added ones have to be visible.
+func (h *WorkoutHandler) save(w http.ResponseWriter, r *http.Request, workout *model.Workout, before []int, status int) {
+	if err := workout.SetGroups(workout.Groups); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	workout, err := h.workoutRepo.GetByID(r.Context(), id)
+	existing := make(map[int]bool, len(before))
+	for _, id := range before {
+		existing[id] = true
+	}
+	var added []int
+	for _, id := range workout.ExerciseIDs() {
+		if !existing[id] {
+			added = append(added, id)
+		}
+	}
+	if !checkExercisesVisible(w, r, h.exerciseRepo, workout.UserID, added) {
+		return
+	}
+	if !checkMeasurements(w, r, h.exerciseRepo, workout.Exercises) {
+		return
+	}
+
+	if err := h.workoutRepo.Update(r.Context(), workout); err != nil {
+		log.Printf("Error updating workout: %v", err)
+		http.Error(w, "Failed to update workout", http.StatusInternalServerError)
+		return
+	}
+	h.detectRecords(r, workout)
+	h.syncProgramTargets(r, workout.ID)
+	h.liveService.Changed(workout)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(workout)
+}
+
+func (h *WorkoutHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(resourceID(r))
 	if err != nil {
-		http.Error(w, "Workout not found", http.StatusNotFound)
+		http.Error(w, "Invalid workout ID", http.StatusBadRequest)
 		return
 	}
 
-	if workout.UserID != userID {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	workout, ok := ownedWorkout(w, r, h.workoutRepo, id)
+	if !ok {
 		return
 	}
 
@@ -186,10 +502,101 @@ func (h *WorkoutHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to delete workout", http.StatusInternalServerError)
 		return
 	}
+	h.liveService.Deleted(workout.UserID, id)
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *WorkoutHandler) Start(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, (*model.Workout).Start)
+}
+
+func (h *WorkoutHandler) Finish(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, (*model.Workout).Finish)
+}
+
+func (h *WorkoutHandler) Skip(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, (*model.Workout).Skip)
+}
+
+func (h *WorkoutHandler) Reopen(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, (*model.Workout).Reopen)
+}
+
+// transition applies a status change to the workout named in the path, or by
+// the id query parameter on the deprecated routes, and persists it. Illegal
+// transitions are answered with 409.
+func (h *WorkoutHandler) transition(w http.ResponseWriter, r *http.Request, apply func(*model.Workout) error) {
+	id, err := strconv.Atoi(resourceID(r))
+	if err != nil {
+		http.Error(w, "Invalid workout ID", http.StatusBadRequest)
+		return
+	}
+
+	workout, ok := ownedWorkout(w, r, h.workoutRepo, id)
+	if !ok {
+		return
+	}
+
+	if err := apply(workout); err != nil {
+		http.Error(w, fmt.Sprintf("Cannot change workout from %s: %v", workout.Status, err), http.StatusConflict)
+		return
+	}
+
+	if err := h.workoutRepo.UpdateStatus(r.Context(), workout); err != nil {
+		log.Printf("Error updating workout status: %v", err)
+		http.Error(w, "Failed to update workout", http.StatusInternalServerError)
+		return
+	}
+	h.detectRecords(r, workout)
+	h.syncProgramTargets(r, workout.ID)
+	h.liveService.Changed(workout)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workout)
+}
+
+// ownedWorkout fetches a workout of the caller, answering the request itself
+// when it cannot. Another user's workout is not found, just like a missing
+// one, so workout IDs do not give away what exists.
+func ownedWorkout(w http.ResponseWriter, r *http.Request, workoutRepo *repository.WorkoutRepository, id int) (*model.Workout, bool) {
+	userID, err := util.GetUserIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	workout, err := workoutRepo.GetByID(r.Context(), id)
+	if err != nil {
+		log.Printf("Error fetching workout: %v", err)
+		http.Error(w, "Failed to fetch workout", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if workout == nil || workout.UserID != userID {
+		http.Error(w, "Workout not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return workout, true
+}
+
+// detectRecords stores any PRs set in the workout and marks the entries
+// that set them. Failing to do so does not fail the request.
+func (h *WorkoutHandler) detectRecords(r *http.Request, workout *model.Workout) {
+	if _, err := h.recordRepo.DetectForWorkout(r.Context(), workout); err != nil {
+		log.Printf("Error detecting personal records: %v", err)
+	}
+}
+
+// syncProgramTargets lets the program a workout belongs to, if any, adjust
+// the targets of its remaining sessions to what was just logged.
+func (h *WorkoutHandler) syncProgramTargets(r *http.Request, workoutID int) {
+	if err := h.programService.SyncTargets(r.Context(), workoutID); err != nil {
+		log.Printf("Error updating program targets: %v", err)
+	}
+}
+
 func (h *WorkoutHandler) GenerateReport(w http.ResponseWriter, r *http.Request) {
 	userID, err := util.GetUserIDFromContext(r.Context())
 	if err != nil {
@@ -198,18 +605,44 @@ func (h *WorkoutHandler) GenerateReport(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Parse query parameters for report customization
-	startDate := r.URL.Query().Get("start_date")
-	endDate := r.URL.Query().Get("end_date")
+	q, err := parseReportQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" && format != "pdf" {
+		http.Error(w, "format must be one of json, csv, pdf", http.StatusBadRequest)
+		return
+	}
 
 	// Generate the report
-	report, err := h.workoutRepo.GenerateReport(r.Context(), userID, startDate, endDate)
+	report, err := h.workoutRepo.GenerateReport(r.Context(), userID, q)
 	if err != nil {
 		log.Printf("Error generating report: %v", err)
 		http.Error(w, "Failed to generate report", http.StatusInternalServerError)
 		return
 	}
 
-	// Send the report as JSON response
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(report)
+	filename := fmt.Sprintf("workout-report-%s-%s", report.StartDate, report.EndDate)
+
+	// Send the report in the requested format
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".csv"))
+		if err := export.WriteReportCSV(w, report); err != nil {
+			log.Printf("Error writing CSV report: %v", err)
+		}
+	case "pdf":
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".pdf"))
+		if err := export.WriteReportPDF(w, report); err != nil {
+			log.Printf("Error writing PDF report: %v", err)
+		}
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
 }
@@ -0,0 +1,375 @@
+// Package importer reads workout history exported from other apps.
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+)
+
+type Format string
+
+const (
+	FormatStrong Format = "strong"
+	FormatHevy   Format = "hevy"
+)
+
+const poundsToKilograms = 0.45359237
+
+var (
+	ErrInvalidFormat = errors.New("format must be one of strong, hevy")
+	ErrInvalidFile   = errors.New("invalid export file")
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatStrong, FormatHevy:
+		return f, nil
+	}
+	return "", ErrInvalidFormat
+}
+
+// Row is one set from an export file.
+type Row struct {
+	Line         int
+	WorkoutName  string
+	StartedAt    time.Time
+	EndedAt      *time.Time
+	WorkoutNotes string
+	ExerciseName string
+	ExerciseNote string
+	SetType      string
+	Weight       float64
+	Reps         int
+	RPE          *float64
+}
+
+// RowError reports why a line of the file could not be imported.
+type RowError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// Workout is a group of rows that make up one session in the export.
+type Workout struct {
+	Name      string
+	Notes     string
+	StartedAt time.Time
+	EndedAt   *time.Time
+	Exercises []Exercise
+}
+
+type Exercise struct {
+	Name  string
+	Notes string
+	Sets  []model.WorkoutSet
+	Lines []int
+}
+
+// columns maps the fields we read to the header names each format uses.
+var columns = map[Format]map[string]string{
+	FormatStrong: {
+		"date":          "date",
+		"workout":       "workout name",
+		"duration":      "duration",
+		"exercise":      "exercise name",
+		"set":           "set order",
+		"weight":        "weight",
+		"reps":          "reps",
+		"notes":         "notes",
+		"workout_notes": "workout notes",
+		"rpe":           "rpe",
+	},
+	FormatHevy: {
+		"date":          "start_time",
+		"end":           "end_time",
+		"workout":       "title",
+		"workout_notes": "description",
+		"exercise":      "exercise_title",
+		"notes":         "exercise_notes",
+		"set":           "set_type",
+		"weight":        "weight_kg",
+		"weight_lbs":    "weight_lbs",
+		"reps":          "reps",
+		"rpe":           "rpe",
+	},
+}
+
+var required = map[Format][]string{
+	FormatStrong: {"date", "workout", "exercise", "weight", "reps"},
+	FormatHevy:   {"date", "workout", "exercise", "reps"},
+}
+
+var dateLayouts = map[Format][]string{
+	FormatStrong: {"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05Z07:00"},
+	FormatHevy:   {"2 Jan 2006, 15:04", "02 Jan 2006, 15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05Z07:00"},
+}
+
+// Parse reads every row of an export. Rows that cannot be read are reported
+// and skipped; an error is only returned when the file itself is unusable.
+func Parse(r io.Reader, format Format, loc *time.Location) ([]Row, []RowError, error) {
+	br := bufio.NewReader(r)
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+
+	// Older Strong exports use semicolons.
+	if first, err := br.Peek(512); err == nil || len(first) > 0 {
+		line := string(first)
+		if i := strings.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i]
+		}
+		if strings.Count(line, ";") > strings.Count(line, ",") {
+			reader.Comma = ';'
+		}
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: reading header: %v", ErrInvalidFile, err)
+	}
+
+	index := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for field, column := range columns[format] {
+			if name == column {
+				index[field] = i
+			}
+		}
+	}
+	for _, field := range required[format] {
+		if _, ok := index[field]; !ok {
+			return nil, nil, fmt.Errorf("%w: missing %q column for %s export", ErrInvalidFile, columns[format][field], format)
+		}
+	}
+
+	var rows []Row
+	var rowErrors []RowError
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Message: err.Error()})
+			continue
+		}
+
+		get := func(field string) string {
+			if i, ok := index[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row, err := parseRow(format, loc, get)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Message: err.Error()})
+			continue
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors, nil
+}
+
+func parseRow(format Format, loc *time.Location, get func(string) string) (Row, error) {
+	row := Row{
+		WorkoutName:  get("workout"),
+		WorkoutNotes: get("workout_notes"),
+		ExerciseName: get("exercise"),
+		ExerciseNote: get("notes"),
+		SetType:      model.SetTypeWorking,
+	}
+
+	if row.WorkoutName == "" {
+		return row, errors.New("workout name is empty")
+	}
+	if row.ExerciseName == "" {
+		return row, errors.New("exercise name is empty")
+	}
+
+	startedAt, err := parseTime(get("date"), dateLayouts[format], loc)
+	if err != nil {
+		return row, err
+	}
+	row.StartedAt = startedAt
+
+	switch format {
+	case FormatStrong:
+		if d, ok := parseStrongDuration(get("duration")); ok {
+			end := startedAt.Add(d)
+			row.EndedAt = &end
+		}
+		row.SetType = strongSetType(get("set"))
+	case FormatHevy:
+		if end := get("end"); end != "" {
+			if endedAt, err := parseTime(end, dateLayouts[format], loc); err == nil {
+				row.EndedAt = &endedAt
+			}
+		}
+		row.SetType = hevySetType(get("set"))
+	}
+
+	weight := get("weight")
+	multiplier := 1.0
+	if weight == "" && get("weight_lbs") != "" {
+		weight = get("weight_lbs")
+		multiplier = poundsToKilograms
+	}
+	if weight != "" {
+		w, err := strconv.ParseFloat(strings.ReplaceAll(weight, ",", "."), 64)
+		if err != nil || w < 0 {
+			return row, fmt.Errorf("invalid weight %q", weight)
+		}
+		row.Weight = math.Round(w*multiplier*100) / 100
+	}
+
+	reps := get("reps")
+	if reps != "" {
+		n, err := strconv.ParseFloat(reps, 64)
+		if err != nil || n < 0 {
+			return row, fmt.Errorf("invalid reps %q", reps)
+		}
+		row.Reps = int(n)
+	}
+	if row.Reps == 0 && row.Weight == 0 {
+		return row, errors.New("set has no reps or weight; timed and distance sets are not supported")
+	}
+
+	if rpe := get("rpe"); rpe != "" {
+		v, err := strconv.ParseFloat(rpe, 64)
+		if err != nil || v < 1 || v > 10 {
+			return row, fmt.Errorf("invalid rpe %q", rpe)
+		}
+		row.RPE = &v
+	}
+
+	return row, nil
+}
+
+// Group assembles rows into workouts, keyed by start time and name, with
+// exercises in the order they first appear. Workouts are sorted by start.
+func Group(rows []Row) []Workout {
+	type key struct {
+		startedAt time.Time
+		name      string
+	}
+
+	var workouts []*Workout
+	byKey := make(map[key]*Workout)
+	for _, row := range rows {
+		k := key{row.StartedAt, row.WorkoutName}
+		workout, ok := byKey[k]
+		if !ok {
+			workout = &Workout{Name: row.WorkoutName, Notes: row.WorkoutNotes, StartedAt: row.StartedAt, EndedAt: row.EndedAt}
+			byKey[k] = workout
+			workouts = append(workouts, workout)
+		}
+
+		var exercise *Exercise
+		for i := range workout.Exercises {
+			if strings.EqualFold(workout.Exercises[i].Name, row.ExerciseName) {
+				exercise = &workout.Exercises[i]
+				break
+			}
+		}
+		if exercise == nil {
+			workout.Exercises = append(workout.Exercises, Exercise{Name: row.ExerciseName})
+			exercise = &workout.Exercises[len(workout.Exercises)-1]
+		}
+		if exercise.Notes == "" {
+			exercise.Notes = row.ExerciseNote
+		}
+
+		exercise.Lines = append(exercise.Lines, row.Line)
+		exercise.Sets = append(exercise.Sets, model.WorkoutSet{
+			SetNumber: len(exercise.Sets) + 1,
+			Reps:      row.Reps,
+			Weight:    row.Weight,
+			RPE:       row.RPE,
+			SetType:   row.SetType,
+			Completed: true,
+		})
+	}
+
+	sort.SliceStable(workouts, func(i, j int) bool {
+		return workouts[i].StartedAt.Before(workouts[j].StartedAt)
+	})
+
+	grouped := make([]Workout, len(workouts))
+	for i, w := range workouts {
+		grouped[i] = *w
+	}
+	return grouped
+}
+
+func parseTime(value string, layouts []string, loc *time.Location) (time.Time, error) {
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+var durationPart = regexp.MustCompile(`(\d+)\s*([hms])`)
+
+// parseStrongDuration reads durations such as "1h 5m" or "45m".
+func parseStrongDuration(value string) (time.Duration, bool) {
+	parts := durationPart.FindAllStringSubmatch(value, -1)
+	if len(parts) == 0 {
+		return 0, false
+	}
+
+	var d time.Duration
+	for _, p := range parts {
+		n, _ := strconv.Atoi(p[1])
+		switch p[2] {
+		case "h":
+			d += time.Duration(n) * time.Hour
+		case "m":
+			d += time.Duration(n) * time.Minute
+		case "s":
+			d += time.Duration(n) * time.Second
+		}
+	}
+	return d, true
+}
+
+// strongSetType reads Strong's "Set Order" column, which is the set number
+// for working sets and a letter for the others.
+func strongSetType(order string) string {
+	switch strings.ToUpper(order) {
+	case "W":
+		return model.SetTypeWarmup
+	case "D":
+		return model.SetTypeDrop
+	case "F":
+		return model.SetTypeFailure
+	}
+	return model.SetTypeWorking
+}
+
+func hevySetType(setType string) string {
+	switch strings.ToLower(setType) {
+	case "warmup":
+		return model.SetTypeWarmup
+	case "dropset":
+		return model.SetTypeDrop
+	case "failure":
+		return model.SetTypeFailure
+	}
+	return model.SetTypeWorking
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/yeboahd24/workout-tracker/repository"
+	"github.com/yeboahd24/workout-tracker/util"
+)
+
+// AdminMiddleware lets only admins through. It goes inside AuthMiddleware,
+// which puts the user ID in the context.
+func AdminMiddleware(userRepo *repository.UserRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, err := util.GetUserIDFromContext(r.Context())
+			if err != nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			user, err := userRepo.GetByID(r.Context(), userID)
+			if err != nil {
+				log.Printf("Error fetching user: %v", err)
+				http.Error(w, "Failed to fetch user", http.StatusInternalServerError)
+				return
+			}
+			if !user.IsAdmin {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
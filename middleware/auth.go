@@ -2,13 +2,15 @@ package middleware
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"strings"
 
+	"github.com/yeboahd24/workout-tracker/repository"
 	"github.com/yeboahd24/workout-tracker/util"
 )
 
-func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
+func AuthMiddleware(jwtSecret string, sessionRepo *repository.SessionRepository) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -23,13 +25,25 @@ func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
 				return
 			}
 
-			userID, err := util.ValidateJWT(bearerToken[1], jwtSecret)
+			userID, sessionID, err := util.ValidateJWT(bearerToken[1], jwtSecret)
 			if err != nil {
 				http.Error(w, "Invalid token", http.StatusUnauthorized)
 				return
 			}
 
+			active, err := sessionRepo.IsActive(r.Context(), sessionID)
+			if err != nil {
+				log.Printf("Error checking session: %v", err)
+				http.Error(w, "Failed to validate session", http.StatusInternalServerError)
+				return
+			}
+			if !active {
+				http.Error(w, "Session has been revoked", http.StatusUnauthorized)
+				return
+			}
+
 			ctx := context.WithValue(r.Context(), "userID", userID)
+			ctx = context.WithValue(ctx, "sessionID", sessionID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
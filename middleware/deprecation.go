@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DeprecationMiddleware marks a route that is being phased out. Responses
+// carry the Deprecation (RFC 9745) and Sunset (RFC 8594) headers and link to
+// the route that replaces it, so clients can tell they should move on
+// before the route goes away.
+func DeprecationMiddleware(successor string, deprecatedAt, sunsetAt time.Time) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", deprecatedAt.Unix()))
+			w.Header().Set("Sunset", sunsetAt.UTC().Format(http.TimeFormat))
+			w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+type OneRepMaxFormula string
+
+const (
+	FormulaEpley    OneRepMaxFormula = "epley"
+	FormulaBrzycki  OneRepMaxFormula = "brzycki"
+	FormulaLombardi OneRepMaxFormula = "lombardi"
+)
+
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// MaxEstimateReps is the highest rep count a set may have to be used for a
+// one-rep max estimate; beyond it the formulas drift apart too much.
+const MaxEstimateReps = 12
+
+var (
+	ErrInvalidFormula  = errors.New("formula must be one of epley, brzycki, lombardi")
+	ErrInvalidInterval = errors.New("interval must be one of day, week, month")
+)
+
+// LoggedSet is one performed set, flattened for analytics.
+type LoggedSet struct {
+	ExerciseID  int
+	PerformedAt time.Time
+	Weight      float64
+	Reps        int
+}
+
+type StrengthPoint struct {
+	Period             time.Time `json:"period"`
+	EstimatedOneRepMax float64   `json:"estimated_1rm"`
+	Weight             float64   `json:"weight"`
+	Reps               int       `json:"reps"`
+}
+
+type StrengthSeries struct {
+	ExerciseID int              `json:"exercise_id"`
+	Formula    OneRepMaxFormula `json:"formula"`
+	Interval   string           `json:"interval"`
+	Points     []StrengthPoint  `json:"points"`
+}
+
+// ParseOneRepMaxFormula defaults to Epley when no formula is given.
+func ParseOneRepMaxFormula(s string) (OneRepMaxFormula, error) {
+	switch f := OneRepMaxFormula(s); f {
+	case "":
+		return FormulaEpley, nil
+	case FormulaEpley, FormulaBrzycki, FormulaLombardi:
+		return f, nil
+	}
+	return "", ErrInvalidFormula
+}
+
+// ParseInterval defaults to the given interval when none is requested.
+func ParseInterval(s, fallback string) (string, error) {
+	switch s {
+	case "":
+		return fallback, nil
+	case IntervalDay, IntervalWeek, IntervalMonth:
+		return s, nil
+	}
+	return "", ErrInvalidInterval
+}
+
+// Estimate returns the estimated one-rep max for a set of reps at weight.
+func (f OneRepMaxFormula) Estimate(weight float64, reps int) float64 {
+	if reps <= 1 {
+		return weight
+	}
+
+	var estimate float64
+	switch f {
+	case FormulaBrzycki:
+		estimate = weight * 36 / float64(37-reps)
+	case FormulaLombardi:
+		estimate = weight * math.Pow(float64(reps), 0.10)
+	default:
+		estimate = weight * (1 + float64(reps)/30)
+	}
+	return math.Round(estimate*100) / 100
+}
+
+// PeriodStart truncates t to the start of its day, ISO week (Monday) or
+// month in t's location.
+func PeriodStart(t time.Time, interval string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch interval {
+	case IntervalWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+	return day
+}
+
+// BuildStrengthSeries keeps the best estimated one-rep max per exercise and
+// period. Series are ordered by exercise, points by period.
+func BuildStrengthSeries(sets []LoggedSet, formula OneRepMaxFormula, interval string) []StrengthSeries {
+	best := make(map[int]map[time.Time]StrengthPoint)
+	for _, s := range sets {
+		if s.Weight <= 0 || s.Reps < 1 || s.Reps > MaxEstimateReps {
+			continue
+		}
+
+		period := PeriodStart(s.PerformedAt, interval)
+		estimate := formula.Estimate(s.Weight, s.Reps)
+
+		if best[s.ExerciseID] == nil {
+			best[s.ExerciseID] = make(map[time.Time]StrengthPoint)
+		}
+		if estimate > best[s.ExerciseID][period].EstimatedOneRepMax {
+			best[s.ExerciseID][period] = StrengthPoint{
+				Period:             period,
+				EstimatedOneRepMax: estimate,
+				Weight:             s.Weight,
+				Reps:               s.Reps,
+			}
+		}
+	}
+
+	series := make([]StrengthSeries, 0, len(best))
+	for exerciseID, periods := range best {
+		points := make([]StrengthPoint, 0, len(periods))
+		for _, p := range periods {
+			points = append(points, p)
+		}
+		sort.Slice(points, func(i, j int) bool { return points[i].Period.Before(points[j].Period) })
+
+		series = append(series, StrengthSeries{
+			ExerciseID: exerciseID,
+			Formula:    formula,
+			Interval:   interval,
+			Points:     points,
+		})
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].ExerciseID < series[j].ExerciseID })
+
+	return series
+}
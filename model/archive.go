@@ -0,0 +1,93 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ArchiveVersion is bumped whenever the archive layout changes in a way an
+// older import could not read.
+const ArchiveVersion = 1
+
+var (
+	ErrUnsupportedArchive = errors.New("unsupported archive version")
+	ErrAccountNotEmpty    = errors.New("account already has workouts")
+)
+
+// Archive is everything we hold for a user, as produced by /me/export.
+type Archive struct {
+	Version    int         `json:"version"`
+	ExportedAt time.Time   `json:"exported_at"`
+	Profile    User        `json:"profile"`
+	Exercises  []*Exercise `json:"exercises"`
+	Workouts   []*Workout  `json:"workouts"`
+	Reports    []*Report   `json:"reports"`
+}
+
+func NewArchive(user *User) *Archive {
+	return &Archive{
+		Version:    ArchiveVersion,
+		ExportedAt: time.Now(),
+		Profile:    *user,
+		Exercises:  []*Exercise{},
+		Workouts:   []*Workout{},
+		Reports:    []*Report{},
+	}
+}
+
+// Validate checks that the archive can be imported: the version is one we
+// understand and every workout entry names an exercise in the archive.
+func (a *Archive) Validate() error {
+	if a.Version < 1 || a.Version > ArchiveVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedArchive, a.Version)
+	}
+
+	exercises := make(map[int]bool, len(a.Exercises))
+	for _, e := range a.Exercises {
+		if e.Name == "" {
+			return fmt.Errorf("exercise %d has no name", e.ID)
+		}
+		exercises[e.ID] = true
+	}
+
+	for _, w := range a.Workouts {
+		if _, err := ParseWorkoutStatus(string(w.Status)); err != nil {
+			return err
+		}
+		for i := range w.Exercises {
+			if !exercises[w.Exercises[i].ExerciseID] {
+				return fmt.Errorf("workout %d refers to unknown exercise %d", w.ID, w.Exercises[i].ExerciseID)
+			}
+			for _, s := range w.Exercises[i].SetLog {
+				if err := s.Validate(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// MissingExerciseIDs lists, in ascending order, the exercises the archive's
+// workouts refer to that are not in the archive yet.
+func (a *Archive) MissingExerciseIDs() []int {
+	included := make(map[int]bool, len(a.Exercises))
+	for _, e := range a.Exercises {
+		included[e.ID] = true
+	}
+
+	var missing []int
+	for _, w := range a.Workouts {
+		for _, e := range w.Exercises {
+			if !included[e.ExerciseID] {
+				included[e.ExerciseID] = true
+				missing = append(missing, e.ExerciseID)
+			}
+		}
+	}
+	sort.Ints(missing)
+	return missing
+}
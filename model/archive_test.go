@@ -0,0 +1,90 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestArchiveMissingExerciseIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		exercises []int
+		workouts  [][]int
+		want      []int
+	}{
+		{"no workouts", []int{1}, nil, nil},
+		{"all included", []int{1, 2}, [][]int{{1, 2}, {2}}, nil},
+		{"missing once each", []int{1}, [][]int{{7, 1}, {3, 7}}, []int{3, 7}},
+		{"nothing included", nil, [][]int{{2}}, []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := &Archive{}
+			for _, id := range tt.exercises {
+				archive.Exercises = append(archive.Exercises, &Exercise{ID: id, Name: "Exercise"})
+			}
+			for i, ids := range tt.workouts {
+				archive.Workouts = append(archive.Workouts, archiveWorkout(i+1, ids...))
+			}
+
+			if got := archive.MissingExerciseIDs(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MissingExerciseIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// An exercise deleted after a workout used it is not in the exercise list
+// the export starts from. Once the export adds it, the archive has to
+// survive the trip through JSON and be accepted by the import.
+func TestArchiveExportImportWithDeletedExercise(t *testing.T) {
+	deletedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	squat := &Exercise{ID: 3, Name: "Squat", MeasurementType: MeasureWeightReps}
+	lunge := &Exercise{ID: 7, Name: "Walking Lunge", MeasurementType: MeasureWeightReps, DeletedAt: &deletedAt}
+
+	archive := NewArchive(&User{ID: 1, Username: "lifter"})
+	archive.Exercises = append(archive.Exercises, squat)
+	archive.Workouts = append(archive.Workouts, archiveWorkout(10, squat.ID, lunge.ID))
+
+	if err := archive.Validate(); err == nil {
+		t.Fatal("Validate() accepted an archive without the deleted exercise")
+	}
+
+	missing := archive.MissingExerciseIDs()
+	if !reflect.DeepEqual(missing, []int{lunge.ID}) {
+		t.Fatalf("MissingExerciseIDs() = %v, want [%d]", missing, lunge.ID)
+	}
+	archive.Exercises = append(archive.Exercises, lunge)
+
+	data, err := json.Marshal(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var imported Archive
+	if err := json.Unmarshal(data, &imported); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := imported.Validate(); err != nil {
+		t.Fatalf("Validate() of the exported archive error = %v", err)
+	}
+	if got := imported.MissingExerciseIDs(); got != nil {
+		t.Errorf("MissingExerciseIDs() after export = %v, want none", got)
+	}
+	if len(imported.Exercises) != 2 || imported.Exercises[1].DeletedAt == nil {
+		t.Errorf("imported exercises = %+v, want the deleted exercise with deleted_at", imported.Exercises)
+	}
+}
+
+func archiveWorkout(id int, exerciseIDs ...int) *Workout {
+	workout := NewWorkout(1, "Workout", "", time.Date(2026, 9, id, 7, 0, 0, 0, time.UTC))
+	workout.ID = id
+	workout.Status = StatusCompleted
+	for _, exerciseID := range exerciseIDs {
+		workout.AddExercise(exerciseID, 3, 5, 100, "")
+	}
+	return workout
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// CalendarFeed gives read-only access to a user's workouts as an iCalendar
+// feed. The token is the only credential, since calendar apps cannot send
+// an Authorization header.
+type CalendarFeed struct {
+	UserID    int       `json:"-"`
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewCalendarFeed(userID int) (*CalendarFeed, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	return &CalendarFeed{
+		UserID:    userID,
+		Token:     hex.EncodeToString(b),
+		CreatedAt: time.Now(),
+	}, nil
+}
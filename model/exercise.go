@@ -1,22 +1,290 @@
 package model
 
-import "time"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
 
+type PromotionStatus string
+
+// A custom exercise can be put forward for the global catalog. An admin
+// approves it, which makes it global, or rejects it.
+const (
+	PromotionNone     PromotionStatus = "none"
+	PromotionPending  PromotionStatus = "pending"
+	PromotionApproved PromotionStatus = "approved"
+	PromotionRejected PromotionStatus = "rejected"
+)
+
+var (
+	ErrExerciseNotCustom   = errors.New("only custom exercises can be promoted")
+	ErrPromotionPending    = errors.New("exercise is already waiting for review")
+	ErrPromotionNotPending = errors.New("exercise is not waiting for review")
+	ErrExerciseDeleted     = errors.New("exercise has been deleted")
+	ErrMergeIntoSelf       = errors.New("cannot merge an exercise into itself")
+	ErrMergeIntoCustom     = errors.New("a catalog exercise can only be merged into another catalog exercise")
+	ErrMergeNotVisible     = errors.New("a custom exercise can only be merged into one its owner can see")
+
+	ErrInvalidMovementPattern = errors.New("movement_pattern must be one of squat, hinge, push, pull, carry")
+	ErrInvalidLaterality      = errors.New("laterality must be one of bilateral, unilateral")
+	ErrMuscleRoleConflict     = errors.New("a muscle group cannot be both primary and secondary")
+	ErrUnknownMuscleGroup     = errors.New("unknown muscle group")
+	ErrUnknownEquipment       = errors.New("unknown equipment")
+)
+
+// Movement patterns and laterality are optional; an empty string means
+// not set.
+const (
+	MovementSquat = "squat"
+	MovementHinge = "hinge"
+	MovementPush  = "push"
+	MovementPull  = "pull"
+	MovementCarry = "carry"
+
+	LateralityBilateral  = "bilateral"
+	LateralityUnilateral = "unilateral"
+)
+
+var (
+	MovementPatterns = []string{MovementSquat, MovementHinge, MovementPush, MovementPull, MovementCarry}
+	Lateralities     = []string{LateralityBilateral, LateralityUnilateral}
+)
+
+// TaxonomyTerm is a muscle group or piece of equipment. Exercises refer to
+// terms by slug.
+type TaxonomyTerm struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// Taxonomy lists the values exercises can be classified with.
+type Taxonomy struct {
+	MuscleGroups     []TaxonomyTerm `json:"muscle_groups"`
+	Equipment        []TaxonomyTerm `json:"equipment"`
+	MovementPatterns []string       `json:"movement_patterns"`
+	Lateralities     []string       `json:"lateralities"`
+}
+
+// Exercise is either part of the global catalog (no owner), visible to
+// everyone, or a custom exercise only its owner can see. A deleted exercise
+// that workouts still refer to is kept, with DeletedAt set, so those
+// workouts still make sense; it is no longer listed or usable. Exercises
+// seeded from the built-in catalog have a Slug that identifies them across
+// catalog updates.
 type Exercise struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Category    string    `json:"category"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID               int             `json:"id"`
+	Slug             *string         `json:"slug,omitempty"`
+	Name             string          `json:"name"`
+	Description      string          `json:"description"`
+	Category         string          `json:"category"`
+	MeasurementType  MeasurementType `json:"measurement_type"`
+	PrimaryMuscles   []string        `json:"primary_muscles"`
+	SecondaryMuscles []string        `json:"secondary_muscles"`
+	Equipment        []string        `json:"equipment"`
+	MovementPattern  string          `json:"movement_pattern"`
+	Laterality       string          `json:"laterality"`
+	OwnerUserID      *int            `json:"owner_user_id"`
+	PromotionStatus  PromotionStatus `json:"promotion_status"`
+	DeletedAt        *time.Time      `json:"deleted_at,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
 }
 
+// NewExercise creates an exercise for the global catalog.
 func NewExercise(name, description, category string) *Exercise {
 	return &Exercise{
-		Name:        name,
-		Description: description,
-		Category:    category,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		Name:             name,
+		Description:      description,
+		Category:         category,
+		MeasurementType:  MeasureWeightReps,
+		PrimaryMuscles:   make([]string, 0),
+		SecondaryMuscles: make([]string, 0),
+		Equipment:        make([]string, 0),
+		PromotionStatus:  PromotionNone,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+}
+
+// NewCustomExercise creates an exercise only its owner can see.
+func NewCustomExercise(ownerUserID int, name, description, category string) *Exercise {
+	exercise := NewExercise(name, description, category)
+	exercise.OwnerUserID = &ownerUserID
+	return exercise
+}
+
+// SetTaxonomy normalizes and checks the exercise's classification. Whether
+// the muscle group and equipment slugs exist is left to the repository.
+func (e *Exercise) SetTaxonomy(primary, secondary, equipment []string, movementPattern, laterality string) error {
+	e.PrimaryMuscles = normalizeSlugs(primary)
+	e.SecondaryMuscles = normalizeSlugs(secondary)
+	e.Equipment = normalizeSlugs(equipment)
+	e.MovementPattern = strings.ToLower(strings.TrimSpace(movementPattern))
+	e.Laterality = strings.ToLower(strings.TrimSpace(laterality))
+
+	if e.MovementPattern != "" && !contains(MovementPatterns, e.MovementPattern) {
+		return ErrInvalidMovementPattern
+	}
+	if e.Laterality != "" && !contains(Lateralities, e.Laterality) {
+		return ErrInvalidLaterality
+	}
+	for _, m := range e.SecondaryMuscles {
+		if contains(e.PrimaryMuscles, m) {
+			return ErrMuscleRoleConflict
+		}
+	}
+	return nil
+}
+
+// normalizeSlugs lower-cases, trims and de-duplicates slugs, keeping their
+// order.
+func normalizeSlugs(slugs []string) []string {
+	normalized := make([]string, 0, len(slugs))
+	for _, s := range slugs {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s != "" && !contains(normalized, s) {
+			normalized = append(normalized, s)
+		}
+	}
+	return normalized
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Exercise) IsGlobal() bool {
+	return e.OwnerUserID == nil
+}
+
+// VisibleTo reports whether the user can see and use the exercise.
+func (e *Exercise) VisibleTo(userID int) bool {
+	return e.IsGlobal() || *e.OwnerUserID == userID
+}
+
+// EditableBy reports whether the user may change, delete or merge the
+// exercise: owners their custom exercises, admins the catalog.
+func (e *Exercise) EditableBy(user *User) bool {
+	if e.IsGlobal() {
+		return user.IsAdmin
+	}
+	return *e.OwnerUserID == user.ID
+}
+
+// CanMergeInto checks that every workout using e could use target instead.
+func (e *Exercise) CanMergeInto(target *Exercise) error {
+	if e.ID == target.ID {
+		return ErrMergeIntoSelf
+	}
+	if target.DeletedAt != nil {
+		return ErrExerciseDeleted
+	}
+	if e.IsGlobal() && !target.IsGlobal() {
+		return ErrMergeIntoCustom
+	}
+	if !e.IsGlobal() && !target.VisibleTo(*e.OwnerUserID) {
+		return ErrMergeNotVisible
+	}
+	return nil
+}
+
+// RequestPromotion puts a custom exercise forward for the global catalog.
+// A rejected exercise can be put forward again.
+func (e *Exercise) RequestPromotion() error {
+	if e.IsGlobal() {
+		return ErrExerciseNotCustom
+	}
+	if e.PromotionStatus == PromotionPending {
+		return ErrPromotionPending
+	}
+	e.PromotionStatus = PromotionPending
+	e.UpdatedAt = time.Now()
+	return nil
+}
+
+// ApprovePromotion moves the exercise into the global catalog. Workouts
+// that already use it are unaffected.
+func (e *Exercise) ApprovePromotion() error {
+	if e.PromotionStatus != PromotionPending {
+		return ErrPromotionNotPending
+	}
+	e.OwnerUserID = nil
+	e.PromotionStatus = PromotionApproved
+	e.UpdatedAt = time.Now()
+	return nil
+}
+
+func (e *Exercise) RejectPromotion() error {
+	if e.PromotionStatus != PromotionPending {
+		return ErrPromotionNotPending
+	}
+	e.PromotionStatus = PromotionRejected
+	e.UpdatedAt = time.Now()
+	return nil
+}
+
+const (
+	DefaultExercisePageSize = 50
+	MaxExercisePageSize     = 200
+)
+
+var (
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrInvalidPageSize = errors.New("limit must be between 1 and 200")
+)
+
+// ExerciseSearch narrows the exercises a user can see. Query matches the
+// name and description as full text, and the name fuzzily so misspellings
+// still find it. MuscleGroup matches primary and secondary muscles alike.
+// Results are sorted by name, case-insensitively, then ID.
+type ExerciseSearch struct {
+	Query           string
+	Category        string
+	MuscleGroup     string
+	Equipment       string
+	MovementPattern string
+	Laterality      string
+	After           *ExerciseCursor
+	Limit           int
+}
+
+// ExerciseCursor is the position after which the next page starts.
+type ExerciseCursor struct {
+	Name string `json:"n"`
+	ID   int    `json:"i"`
+}
+
+// ExercisePage is one page of search results. NextCursor is empty on the
+// last page.
+type ExercisePage struct {
+	Exercises  []*Exercise `json:"exercises"`
+	Total      int         `json:"total"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// String encodes the cursor as an opaque URL-safe token.
+func (c *ExerciseCursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func ParseExerciseCursor(s string) (*ExerciseCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor ExerciseCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID <= 0 {
+		return nil, ErrInvalidCursor
 	}
+	return &cursor, nil
 }
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"errors"
+	"strings"
+)
+
+type GroupType string
+
+// A superset pairs two exercises, a giant set chains three or more, and a
+// circuit goes through two or more, each for a number of rounds.
+const (
+	GroupSuperset GroupType = "superset"
+	GroupGiantSet GroupType = "giant_set"
+	GroupCircuit  GroupType = "circuit"
+)
+
+const maxGroupLabelLength = 10
+
+var (
+	ErrInvalidGroupType   = errors.New("group type must be one of superset, giant_set, circuit")
+	ErrInvalidGroup       = errors.New("a group needs a unique label of up to 10 characters, and rounds and rest_seconds must not be negative")
+	ErrUnknownGroup       = errors.New("exercise refers to a group the workout does not define")
+	ErrInvalidGroupSize   = errors.New("a superset has two exercises, a giant set at least three and a circuit at least two")
+	ErrGroupNotContiguous = errors.New("the exercises of a group must follow one another")
+)
+
+// ExerciseGroup is a set of consecutive workout exercises done back to back.
+// Exercises refer to their group by Label, e.g. A or B. RestSeconds is the
+// rest after each round.
+type ExerciseGroup struct {
+	ID          int       `json:"id"`
+	WorkoutID   int       `json:"workout_id"`
+	Label       string    `json:"label"`
+	Type        GroupType `json:"type"`
+	Rounds      int       `json:"rounds"`
+	RestSeconds int       `json:"rest_seconds"`
+}
+
+// SetGroups replaces the workout's groups and numbers its exercises in
+// order. Rounds default to one. Every group must be used by the right
+// number of consecutive exercises, and every exercise's group must exist.
+func (w *Workout) SetGroups(groups []ExerciseGroup) error {
+	if groups == nil {
+		groups = make([]ExerciseGroup, 0)
+	}
+
+	members := make(map[string]int, len(groups))
+	for i := range groups {
+		g := &groups[i]
+		g.Label = strings.TrimSpace(g.Label)
+		if g.Rounds == 0 {
+			g.Rounds = 1
+		}
+		switch g.Type {
+		case GroupSuperset, GroupGiantSet, GroupCircuit:
+		default:
+			return ErrInvalidGroupType
+		}
+		if _, dup := members[g.Label]; dup || g.Label == "" || len(g.Label) > maxGroupLabelLength ||
+			g.Rounds < 0 || g.RestSeconds < 0 {
+			return ErrInvalidGroup
+		}
+		members[g.Label] = 0
+	}
+
+	var previous string
+	for i := range w.Exercises {
+		e := &w.Exercises[i]
+		e.Position = i + 1
+		e.Group = strings.TrimSpace(e.Group)
+		if e.Group != "" {
+			count, ok := members[e.Group]
+			if !ok {
+				return ErrUnknownGroup
+			}
+			if count > 0 && previous != e.Group {
+				return ErrGroupNotContiguous
+			}
+			members[e.Group] = count + 1
+		}
+		previous = e.Group
+	}
+
+	for _, g := range groups {
+		n := members[g.Label]
+		if (g.Type == GroupSuperset && n != 2) || (g.Type == GroupGiantSet && n < 3) || (g.Type == GroupCircuit && n < 2) {
+			return ErrInvalidGroupSize
+		}
+	}
+
+	w.Groups = groups
+	return nil
+}
@@ -0,0 +1,223 @@
+package model
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// DefaultRestSeconds is the rest between the sets of an exercise that is not
+// part of a group, unless the client asks for another.
+const DefaultRestSeconds = 90
+
+// Events sent to the devices following a live workout session.
+const (
+	LiveSnapshot     = "snapshot"
+	LiveSetCompleted = "set_completed"
+	LiveRestTick     = "rest_tick"
+	LiveRestFinished = "rest_finished"
+	LiveNextSet      = "next_set"
+	LiveEnded        = "ended"
+)
+
+var (
+	ErrNotLive      = errors.New("workout is not in progress")
+	ErrUnknownEntry = errors.New("workout_exercise_id is not an exercise of this workout")
+	ErrInvalidRest  = errors.New("rest_seconds must not be negative")
+)
+
+// LiveEvent is a single message of a live session stream. Which fields are
+// set depends on Type: a snapshot carries the whole workout, set_completed
+// the logged set and any records it set, and the timer events the rest.
+type LiveEvent struct {
+	Type      string       `json:"type"`
+	WorkoutID int          `json:"workout_id"`
+	At        time.Time    `json:"at"`
+	Workout   *Workout     `json:"workout,omitempty"`
+	Set       *WorkoutSet  `json:"set,omitempty"`
+	Records   []RecordType `json:"records,omitempty"`
+	Rest      *RestTimer   `json:"rest,omitempty"`
+	Next      *NextSet     `json:"next,omitempty"`
+}
+
+// RestTimer is the rest running after a completed set.
+type RestTimer struct {
+	Seconds   int       `json:"seconds"`
+	Remaining int       `json:"remaining"`
+	EndsAt    time.Time `json:"ends_at"`
+}
+
+// NextSet is the set the athlete should do next, with the targets planned
+// for it.
+type NextSet struct {
+	WorkoutExerciseID int     `json:"workout_exercise_id"`
+	ExerciseID        int     `json:"exercise_id"`
+	Group             string  `json:"group,omitempty"`
+	SetNumber         int     `json:"set_number"`
+	Reps              int     `json:"reps"`
+	Weight            float64 `json:"weight"`
+	Distance          float64 `json:"distance"`
+	Duration          int     `json:"duration"`
+}
+
+// LogSet records a completed set of one of the workout's entries. A set
+// without a number fills the first planned set that is not done yet, or
+// is added after the last one; a set with the number of a logged set
+// replaces it.
+func (w *Workout) LogSet(workoutExerciseID int, set WorkoutSet) (*WorkoutSet, error) {
+	entry := w.entry(workoutExerciseID)
+	if entry == nil {
+		return nil, ErrUnknownEntry
+	}
+
+	set.WorkoutExerciseID = entry.ID
+	set.Completed = true
+	if set.SetNumber == 0 {
+		set.SetNumber = entry.nextSetNumber()
+	}
+	if set.SetType == "" {
+		set.SetType = SetTypeWorking
+		for _, s := range entry.SetLog {
+			if s.SetNumber == set.SetNumber {
+				set.SetType = s.SetType
+			}
+		}
+	}
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+
+	for i := range entry.SetLog {
+		if entry.SetLog[i].SetNumber == set.SetNumber {
+			set.ID = entry.SetLog[i].ID
+			entry.SetLog[i] = set
+			return &entry.SetLog[i], nil
+		}
+	}
+	entry.SetLog = append(entry.SetLog, set)
+	sort.Slice(entry.SetLog, func(i, j int) bool {
+		return entry.SetLog[i].SetNumber < entry.SetLog[j].SetNumber
+	})
+	for i := range entry.SetLog {
+		if entry.SetLog[i].SetNumber == set.SetNumber {
+			return &entry.SetLog[i], nil
+		}
+	}
+	return nil, ErrUnknownEntry
+}
+
+// NextSet walks the exercises in order and returns the first set still to
+// do, or nil when the workout is done. The exercises of a group take turns:
+// the member with the fewest completed sets goes next.
+func (w *Workout) NextSet() *NextSet {
+	for i := 0; i < len(w.Exercises); {
+		end := i + 1
+		if group := w.Exercises[i].Group; group != "" {
+			for end < len(w.Exercises) && w.Exercises[end].Group == group {
+				end++
+			}
+		}
+
+		var next *WorkoutExercise
+		for j := i; j < end; j++ {
+			entry := &w.Exercises[j]
+			if entry.completedSets() >= entry.plannedSets() {
+				continue
+			}
+			if next == nil || entry.completedSets() < next.completedSets() {
+				next = entry
+			}
+		}
+		if next != nil {
+			return next.nextSet()
+		}
+		i = end
+	}
+	return nil
+}
+
+// RestAfter is the rest due after a set of the given entry when next comes
+// after it. Group members follow one another without rest, and the group's
+// rest comes at the end of each round. There is no rest after the last set.
+func (w *Workout) RestAfter(workoutExerciseID int, next *NextSet) int {
+	entry := w.entry(workoutExerciseID)
+	if entry == nil || next == nil {
+		return 0
+	}
+	if entry.Group == "" {
+		return DefaultRestSeconds
+	}
+
+	if nextEntry := w.entry(next.WorkoutExerciseID); nextEntry != nil &&
+		nextEntry.Group == entry.Group && nextEntry.Position > entry.Position {
+		return 0
+	}
+	for _, g := range w.Groups {
+		if g.Label == entry.Group {
+			return g.RestSeconds
+		}
+	}
+	return 0
+}
+
+func (w *Workout) entry(workoutExerciseID int) *WorkoutExercise {
+	for i := range w.Exercises {
+		if w.Exercises[i].ID == workoutExerciseID {
+			return &w.Exercises[i]
+		}
+	}
+	return nil
+}
+
+// plannedSets is the number of sets prescribed for the entry, counting
+// sets logged beyond the prescription.
+func (we *WorkoutExercise) plannedSets() int {
+	if len(we.SetLog) > we.Sets {
+		return len(we.SetLog)
+	}
+	return we.Sets
+}
+
+func (we *WorkoutExercise) completedSets() int {
+	var n int
+	for _, s := range we.SetLog {
+		if s.Completed {
+			n++
+		}
+	}
+	return n
+}
+
+func (we *WorkoutExercise) nextSetNumber() int {
+	var last int
+	for _, s := range we.SetLog {
+		if !s.Completed {
+			return s.SetNumber
+		}
+		if s.SetNumber > last {
+			last = s.SetNumber
+		}
+	}
+	return last + 1
+}
+
+// nextSet describes the entry's next set, taking the targets from the
+// planned set when one was logged ahead and from the summary otherwise.
+func (we *WorkoutExercise) nextSet() *NextSet {
+	next := &NextSet{
+		WorkoutExerciseID: we.ID,
+		ExerciseID:        we.ExerciseID,
+		Group:             we.Group,
+		SetNumber:         we.nextSetNumber(),
+		Reps:              we.Reps,
+		Weight:            we.Weight,
+		Distance:          we.Distance,
+		Duration:          we.Duration,
+	}
+	for _, s := range we.SetLog {
+		if s.SetNumber == next.SetNumber {
+			next.Reps, next.Weight, next.Distance, next.Duration = s.Reps, s.Weight, s.Distance, s.Duration
+		}
+	}
+	return next
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MeasurementType says what is recorded for each set of an exercise.
+// Distances are in metres and durations in seconds.
+type MeasurementType string
+
+const (
+	MeasureWeightReps       MeasurementType = "weight_reps"
+	MeasureReps             MeasurementType = "reps"
+	MeasureDuration         MeasurementType = "duration"
+	MeasureDistanceDuration MeasurementType = "distance_duration"
+	MeasureWeightDistance   MeasurementType = "weight_distance"
+	MeasureWeightDuration   MeasurementType = "weight_duration"
+)
+
+var MeasurementTypes = []MeasurementType{
+	MeasureWeightReps, MeasureReps, MeasureDuration,
+	MeasureDistanceDuration, MeasureWeightDistance, MeasureWeightDuration,
+}
+
+var ErrInvalidMeasurementType = errors.New("measurement_type must be one of weight_reps, reps, duration, distance_duration, weight_distance, weight_duration")
+
+// ParseMeasurementType defaults to weight×reps, which every exercise was
+// before measurement types existed.
+func ParseMeasurementType(s string) (MeasurementType, error) {
+	if s == "" {
+		return MeasureWeightReps, nil
+	}
+	for _, t := range MeasurementTypes {
+		if MeasurementType(s) == t {
+			return t, nil
+		}
+	}
+	return "", ErrInvalidMeasurementType
+}
+
+func (t MeasurementType) TracksWeight() bool {
+	return t == MeasureWeightReps || t == MeasureWeightDistance || t == MeasureWeightDuration
+}
+
+func (t MeasurementType) TracksReps() bool {
+	return t == MeasureWeightReps || t == MeasureReps
+}
+
+func (t MeasurementType) TracksDistance() bool {
+	return t == MeasureDistanceDuration || t == MeasureWeightDistance
+}
+
+func (t MeasurementType) TracksDuration() bool {
+	return t == MeasureDuration || t == MeasureDistanceDuration || t == MeasureWeightDuration
+}
+
+// MeasurementError reports a value logged for an exercise that does not
+// measure it.
+type MeasurementError struct {
+	ExerciseID int
+	Field      string
+	Type       MeasurementType
+}
+
+func (e *MeasurementError) Error() string {
+	return fmt.Sprintf("exercise %d is measured as %s and does not take %s", e.ExerciseID, e.Type, e.Field)
+}
+
+// CheckMeasurement rejects an entry, or one of its logged sets, that
+// records something the exercise's measurement type does not track.
+// Missing values are allowed so planned workouts can leave them out.
+func (we *WorkoutExercise) CheckMeasurement(t MeasurementType) error {
+	check := func(weight float64, reps int, distance float64, duration int) error {
+		var field string
+		switch {
+		case weight != 0 && !t.TracksWeight():
+			field = "weight"
+		case reps != 0 && !t.TracksReps():
+			field = "reps"
+		case distance != 0 && !t.TracksDistance():
+			field = "distance"
+		case duration != 0 && !t.TracksDuration():
+			field = "duration"
+		default:
+			return nil
+		}
+		return &MeasurementError{ExerciseID: we.ExerciseID, Field: field, Type: t}
+	}
+
+	if err := check(we.Weight, we.Reps, we.Distance, we.Duration); err != nil {
+		return err
+	}
+	for _, s := range we.SetLog {
+		if err := check(s.Weight, s.Reps, s.Distance, s.Duration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cardio returns the distance and duration covered by the entry. Logged sets
+// take precedence over the summary, which is per set. Paced is the part of
+// the distance and time recorded together, which is what pace is based on.
+func (we *WorkoutExercise) Cardio() (distance float64, duration int, pacedDistance float64, pacedDuration int) {
+	add := func(n int, d float64, t int) {
+		distance += float64(n) * d
+		duration += n * t
+		if d > 0 && t > 0 {
+			pacedDistance += float64(n) * d
+			pacedDuration += n * t
+		}
+	}
+
+	if len(we.SetLog) == 0 {
+		add(we.Sets, we.Distance, we.Duration)
+		return
+	}
+	for _, s := range we.SetLog {
+		if s.CountsTowardVolume() {
+			add(1, s.Distance, s.Duration)
+		}
+	}
+	return
+}
+
+// Pace is the time in seconds per kilometre, or zero without a distance.
+func Pace(distance float64, duration int) float64 {
+	if distance <= 0 {
+		return 0
+	}
+	return round2(float64(duration) / (distance / 1000))
+}
@@ -0,0 +1,325 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+type ProgressionRule string
+
+const (
+	ProgressionLinear     ProgressionRule = "linear"
+	ProgressionPercentage ProgressionRule = "percentage"
+	ProgressionDouble     ProgressionRule = "double_progression"
+)
+
+// DefaultIncrement is the load added when a progression rule does not say.
+const DefaultIncrement = 2.5
+
+var (
+	ErrInvalidProgression = errors.New("progression rule must be one of linear, percentage, double_progression")
+	ErrInvalidPercentages = errors.New("percentage progression needs a training_max and percentages between 0 and 1.2")
+	ErrInvalidRepRange    = errors.New("double progression needs 0 < rep_range_min <= rep_range_max")
+	ErrInvalidProgramDay  = errors.New("program days need a week between 1 and weeks and a day between 1 and 7")
+	ErrDuplicateDay       = errors.New("program has the same week and day twice")
+)
+
+type Program struct {
+	ID          int          `json:"id"`
+	UserID      int          `json:"user_id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Weeks       int          `json:"weeks"`
+	Days        []ProgramDay `json:"days"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+type ProgramDay struct {
+	ID        int               `json:"id"`
+	ProgramID int               `json:"program_id"`
+	Week      int               `json:"week"`
+	Day       int               `json:"day"`
+	Name      string            `json:"name"`
+	Exercises []ProgramExercise `json:"exercises"`
+}
+
+type ProgramExercise struct {
+	ID           int         `json:"id"`
+	ProgramDayID int         `json:"program_day_id"`
+	ExerciseID   int         `json:"exercise_id"`
+	Position     int         `json:"position"`
+	Sets         int         `json:"sets"`
+	Reps         int         `json:"reps"`
+	Progression  Progression `json:"progression"`
+}
+
+// Progression describes how the target load of an exercise moves from one
+// session to the next.
+//
+//   - linear: start at StartWeight and add Increment after every session in
+//     which all prescribed sets and reps were completed.
+//   - percentage: work at TrainingMax × Percentages[week], cycling through the
+//     percentages; the training max goes up by Increment after each wave
+//     whose last session hit its target.
+//   - double_progression: add a rep each session until every set reaches
+//     RepRangeMax, then add Increment and drop back to RepRangeMin.
+type Progression struct {
+	Rule        ProgressionRule `json:"rule"`
+	StartWeight float64         `json:"start_weight"`
+	Increment   float64         `json:"increment"`
+	TrainingMax float64         `json:"training_max,omitempty"`
+	Percentages []float64       `json:"percentages,omitempty"`
+	RepRangeMin int             `json:"rep_range_min,omitempty"`
+	RepRangeMax int             `json:"rep_range_max,omitempty"`
+}
+
+type ProgramEnrollment struct {
+	ID        int                 `json:"id"`
+	ProgramID int                 `json:"program_id"`
+	UserID    int                 `json:"user_id"`
+	StartDate time.Time           `json:"start_date"`
+	Workouts  []EnrollmentWorkout `json:"workouts"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+// EnrollmentWorkout links a scheduled workout back to the program day it
+// was generated from.
+type EnrollmentWorkout struct {
+	WorkoutID    int `json:"workout_id"`
+	ProgramDayID int `json:"program_day_id"`
+	Week         int `json:"week"`
+	Day          int `json:"day"`
+}
+
+// Target is the prescription for one exercise in one session.
+type Target struct {
+	Sets   int     `json:"sets"`
+	Reps   int     `json:"reps"`
+	Weight float64 `json:"weight"`
+}
+
+// SessionResult is what was prescribed and what was actually lifted for an
+// exercise in one session.
+type SessionResult struct {
+	Week   int
+	Target Target
+	Weight float64
+	Reps   []int
+}
+
+func NewProgram(userID int, name, description string, weeks int) *Program {
+	return &Program{
+		UserID:      userID,
+		Name:        name,
+		Description: description,
+		Weeks:       weeks,
+		Days:        make([]ProgramDay, 0),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+func NewProgramEnrollment(programID, userID int, startDate time.Time) *ProgramEnrollment {
+	return &ProgramEnrollment{
+		ProgramID: programID,
+		UserID:    userID,
+		StartDate: startDate,
+		Workouts:  make([]EnrollmentWorkout, 0),
+		CreatedAt: time.Now(),
+	}
+}
+
+// Validate checks the program's layout and fills in progression defaults.
+func (p *Program) Validate() error {
+	seen := make(map[[2]int]bool, len(p.Days))
+	for i := range p.Days {
+		day := &p.Days[i]
+		if day.Week < 1 || day.Week > p.Weeks || day.Day < 1 || day.Day > 7 {
+			return ErrInvalidProgramDay
+		}
+		key := [2]int{day.Week, day.Day}
+		if seen[key] {
+			return ErrDuplicateDay
+		}
+		seen[key] = true
+
+		for j := range day.Exercises {
+			day.Exercises[j].Position = j + 1
+			if err := day.Exercises[j].Progression.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ScheduledFor returns when the given program day falls for an enrollment
+// starting on start. Day 1 of week 1 is the start date itself.
+func (d *ProgramDay) ScheduledFor(start time.Time) time.Time {
+	return start.AddDate(0, 0, (d.Week-1)*7+d.Day-1)
+}
+
+func (p *Progression) Validate() error {
+	switch p.Rule {
+	case ProgressionLinear:
+	case ProgressionPercentage:
+		if p.TrainingMax <= 0 || len(p.Percentages) == 0 {
+			return ErrInvalidPercentages
+		}
+		for _, pct := range p.Percentages {
+			if pct <= 0 || pct > 1.2 {
+				return ErrInvalidPercentages
+			}
+		}
+	case ProgressionDouble:
+		if p.RepRangeMin <= 0 || p.RepRangeMin > p.RepRangeMax {
+			return ErrInvalidRepRange
+		}
+	default:
+		return ErrInvalidProgression
+	}
+	if p.Increment == 0 {
+		p.Increment = DefaultIncrement
+	}
+	return nil
+}
+
+// NextTarget computes the prescription for a session in the given program
+// week from the results of the earlier sessions of the same exercise.
+func (p *Progression) NextTarget(sets, reps, week int, history []SessionResult) Target {
+	switch p.Rule {
+	case ProgressionPercentage:
+		wave := len(p.Percentages)
+		cycle := (week - 1) / wave
+		trainingMax := p.TrainingMax
+		for c := 0; c < cycle; c++ {
+			if last, ok := lastInCycle(history, c, wave); ok && last.HitTarget() {
+				trainingMax += p.Increment
+			}
+		}
+		return Target{
+			Sets:   sets,
+			Reps:   reps,
+			Weight: RoundToIncrement(trainingMax*p.Percentages[(week-1)%wave], DefaultIncrement),
+		}
+
+	case ProgressionDouble:
+		if len(history) == 0 {
+			return Target{Sets: sets, Reps: p.RepRangeMin, Weight: p.StartWeight}
+		}
+		last := history[len(history)-1]
+		if last.AllSetsReached(p.RepRangeMax) {
+			return Target{Sets: sets, Reps: p.RepRangeMin, Weight: last.Weight + p.Increment}
+		}
+		next := last.MinReps() + 1
+		if next < p.RepRangeMin {
+			next = p.RepRangeMin
+		}
+		if next > p.RepRangeMax {
+			next = p.RepRangeMax
+		}
+		return Target{Sets: sets, Reps: next, Weight: last.Weight}
+
+	default:
+		if len(history) == 0 {
+			return Target{Sets: sets, Reps: reps, Weight: p.StartWeight}
+		}
+		last := history[len(history)-1]
+		if last.HitTarget() {
+			return Target{Sets: sets, Reps: reps, Weight: last.Weight + p.Increment}
+		}
+		return Target{Sets: sets, Reps: reps, Weight: last.Weight}
+	}
+}
+
+// ProjectedResult is the result assumed for a session that has not been
+// done yet, so that later sessions can be planned as if it succeeded.
+func (t Target) ProjectedResult(week int) SessionResult {
+	reps := make([]int, t.Sets)
+	for i := range reps {
+		reps[i] = t.Reps
+	}
+	return SessionResult{Week: week, Target: t, Weight: t.Weight, Reps: reps}
+}
+
+// ResultFromEntry reads what was lifted from a logged workout exercise.
+// Completed working sets at the top weight count when sets were logged
+// individually; otherwise the sets×reps×weight summary is used.
+func ResultFromEntry(we *WorkoutExercise, week int, target Target) SessionResult {
+	result := SessionResult{Week: week, Target: target}
+
+	if len(we.SetLog) == 0 {
+		result.Weight = we.Weight
+		for i := 0; i < we.Sets; i++ {
+			result.Reps = append(result.Reps, we.Reps)
+		}
+		return result
+	}
+
+	for _, s := range we.SetLog {
+		if s.CountsTowardVolume() && s.Weight > result.Weight {
+			result.Weight = s.Weight
+		}
+	}
+	for _, s := range we.SetLog {
+		if s.CountsTowardVolume() && s.Weight == result.Weight {
+			result.Reps = append(result.Reps, s.Reps)
+		}
+	}
+	return result
+}
+
+// HitTarget reports whether every prescribed set was done for the
+// prescribed reps at no less than the prescribed weight.
+func (r SessionResult) HitTarget() bool {
+	return r.Weight >= r.Target.Weight && len(r.Reps) >= r.Target.Sets && r.AllSetsReached(r.Target.Reps)
+}
+
+func (r SessionResult) AllSetsReached(reps int) bool {
+	if len(r.Reps) == 0 {
+		return false
+	}
+	for _, n := range r.Reps {
+		if n < reps {
+			return false
+		}
+	}
+	return true
+}
+
+func (r SessionResult) MinReps() int {
+	min := 0
+	for i, n := range r.Reps {
+		if i == 0 || n < min {
+			min = n
+		}
+	}
+	return min
+}
+
+// RoundToIncrement rounds a load to the nearest loadable step.
+func RoundToIncrement(weight, step float64) float64 {
+	return math.Round(weight/step) * step
+}
+
+func lastInCycle(history []SessionResult, cycle, wave int) (SessionResult, bool) {
+	for i := len(history) - 1; i >= 0; i-- {
+		if (history[i].Week-1)/wave == cycle {
+			return history[i], true
+		}
+	}
+	return SessionResult{}, false
+}
+
+// ExerciseIDs lists the exercises used on any day of the program.
+func (p *Program) ExerciseIDs() []int {
+	var ids []int
+	for _, d := range p.Days {
+		for _, e := range d.Exercises {
+			ids = append(ids, e.ExerciseID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,95 @@
+package model
+
+import "time"
+
+type RecordType string
+
+const (
+	Record1RM               RecordType = "1rm"
+	Record3RM               RecordType = "3rm"
+	Record5RM               RecordType = "5rm"
+	RecordMaxVolume         RecordType = "max_volume"
+	RecordMaxRepsBodyweight RecordType = "max_reps_bodyweight"
+)
+
+// RecordTypes lists every record type in the order they are reported.
+var RecordTypes = []RecordType{Record1RM, Record3RM, Record5RM, RecordMaxVolume, RecordMaxRepsBodyweight}
+
+// repMaxRecords maps each rep-max record to the reps a set needs to count.
+var repMaxRecords = map[RecordType]int{
+	Record1RM: 1,
+	Record3RM: 3,
+	Record5RM: 5,
+}
+
+// PersonalRecord is a PR event: the first time a value beat every earlier
+// record of the same type for the exercise.
+type PersonalRecord struct {
+	ID                int        `json:"id"`
+	UserID            int        `json:"user_id"`
+	ExerciseID        int        `json:"exercise_id"`
+	WorkoutID         int        `json:"workout_id"`
+	WorkoutExerciseID int        `json:"workout_exercise_id"`
+	Type              RecordType `json:"type"`
+	Value             float64    `json:"value"`
+	Weight            float64    `json:"weight"`
+	Reps              int        `json:"reps"`
+	AchievedAt        time.Time  `json:"achieved_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// ExerciseRecords groups an exercise's PR history with its current bests.
+type ExerciseRecords struct {
+	ExerciseID int                           `json:"exercise_id"`
+	Best       map[RecordType]PersonalRecord `json:"best"`
+	History    []PersonalRecord              `json:"history"`
+}
+
+// PerformedSets returns the sets of an entry that were actually done.
+// Individually logged sets count when marked completed; the sets×reps×weight
+// summary only counts when the caller knows the workout was done.
+func (we *WorkoutExercise) PerformedSets(includeSummary bool) []WorkoutSet {
+	var sets []WorkoutSet
+	if len(we.SetLog) > 0 {
+		for _, s := range we.SetLog {
+			if s.CountsTowardVolume() {
+				sets = append(sets, s)
+			}
+		}
+		return sets
+	}
+
+	if includeSummary {
+		for i := 0; i < we.Sets; i++ {
+			sets = append(sets, WorkoutSet{SetNumber: i + 1, Reps: we.Reps, Weight: we.Weight, SetType: SetTypeWorking, Completed: true})
+		}
+	}
+	return sets
+}
+
+// CandidateRecords returns the best value of every record type reached in
+// the given sets. Types the sets do not qualify for are left out.
+func CandidateRecords(sets []WorkoutSet) map[RecordType]PersonalRecord {
+	candidates := make(map[RecordType]PersonalRecord)
+
+	var volume float64
+	for _, s := range sets {
+		volume += float64(s.Reps) * s.Weight
+
+		if s.Weight > 0 {
+			for recordType, reps := range repMaxRecords {
+				if s.Reps >= reps && s.Weight > candidates[recordType].Value {
+					candidates[recordType] = PersonalRecord{Type: recordType, Value: s.Weight, Weight: s.Weight, Reps: s.Reps}
+				}
+			}
+		} else if s.Reps > 0 && float64(s.Reps) > candidates[RecordMaxRepsBodyweight].Value {
+			candidates[RecordMaxRepsBodyweight] = PersonalRecord{Type: RecordMaxRepsBodyweight, Value: float64(s.Reps), Reps: s.Reps}
+		}
+	}
+
+	if volume > 0 {
+		candidates[RecordMaxVolume] = PersonalRecord{Type: RecordMaxVolume, Value: volume}
+	}
+
+	return candidates
+}
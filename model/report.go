@@ -0,0 +1,317 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+var ErrInvalidDateRange = errors.New("start_date must not be after end_date")
+
+// ReportQuery selects the workouts a report covers.
+type ReportQuery struct {
+	Start   time.Time
+	End     time.Time
+	Status  WorkoutStatus
+	GroupBy string
+}
+
+type Report struct {
+	StartDate        string            `json:"start_date"`
+	EndDate          string            `json:"end_date"`
+	Status           WorkoutStatus     `json:"status,omitempty"`
+	GroupBy          string            `json:"group_by,omitempty"`
+	TotalWorkouts    int               `json:"total_workouts"`
+	TotalExercises   int               `json:"total_exercises"`
+	TotalSets        int               `json:"total_sets"`
+	TotalVolume      float64           `json:"total_volume"`
+	TotalDistance    float64           `json:"total_distance"`
+	TotalDuration    int               `json:"total_duration"`
+	SessionsPerWeek  float64           `json:"sessions_per_week"`
+	AverageDensity   float64           `json:"average_density"`
+	VolumeByExercise []ExerciseVolume  `json:"volume_by_exercise"`
+	VolumeByCategory []CategoryVolume  `json:"volume_by_category"`
+	MuscleGroupSets  []MuscleGroupSets `json:"sets_by_muscle_group"`
+	Cardio           []CardioSummary   `json:"cardio_by_exercise"`
+	BestSets         []BestSet         `json:"best_sets"`
+	Buckets          []ReportBucket    `json:"buckets,omitempty"`
+	Workouts         []ReportWorkout   `json:"workouts"`
+}
+
+type ReportWorkout struct {
+	ID           int           `json:"id"`
+	Name         string        `json:"name"`
+	ScheduledFor time.Time     `json:"scheduled_for"`
+	Status       WorkoutStatus `json:"status"`
+	StartedAt    *time.Time    `json:"started_at,omitempty"`
+	CompletedAt  *time.Time    `json:"completed_at,omitempty"`
+	Volume       float64       `json:"volume"`
+	Exercises    []ReportEntry `json:"exercises"`
+}
+
+type ReportEntry struct {
+	WorkoutExercise
+	ExerciseName     string   `json:"exercise_name"`
+	Category         string   `json:"category"`
+	PrimaryMuscles   []string `json:"primary_muscles"`
+	SecondaryMuscles []string `json:"secondary_muscles"`
+	Volume           float64  `json:"volume"`
+}
+
+type ExerciseVolume struct {
+	ExerciseID   int     `json:"exercise_id"`
+	ExerciseName string  `json:"exercise_name"`
+	Sets         int     `json:"sets"`
+	Volume       float64 `json:"volume"`
+}
+
+type CategoryVolume struct {
+	Category string  `json:"category"`
+	Sets     int     `json:"sets"`
+	Volume   float64 `json:"volume"`
+}
+
+// CardioSummary totals the distance (metres) and time (seconds) logged for
+// an exercise. Pace is seconds per kilometre over the sets that recorded
+// both.
+type CardioSummary struct {
+	ExerciseID   int     `json:"exercise_id"`
+	ExerciseName string  `json:"exercise_name"`
+	Distance     float64 `json:"distance"`
+	Duration     int     `json:"duration"`
+	Pace         float64 `json:"pace,omitempty"`
+
+	pacedDistance float64
+	pacedDuration int
+}
+
+// MuscleGroupSets counts the performed sets that trained a muscle group in
+// a week starting on Monday. Sets of exercises that only work it as a
+// secondary muscle are counted separately.
+type MuscleGroupSets struct {
+	Week          time.Time `json:"week"`
+	MuscleGroup   string    `json:"muscle_group"`
+	Sets          int       `json:"sets"`
+	SecondarySets int       `json:"secondary_sets"`
+}
+
+// BestSet is the heaviest set of an exercise in the report period.
+type BestSet struct {
+	ExerciseID         int       `json:"exercise_id"`
+	ExerciseName       string    `json:"exercise_name"`
+	WorkoutID          int       `json:"workout_id"`
+	Date               time.Time `json:"date"`
+	Weight             float64   `json:"weight"`
+	Reps               int       `json:"reps"`
+	EstimatedOneRepMax float64   `json:"estimated_1rm"`
+}
+
+type ReportBucket struct {
+	Period   time.Time `json:"period"`
+	Workouts int       `json:"workouts"`
+	Sets     int       `json:"sets"`
+	Volume   float64   `json:"volume"`
+}
+
+// BuildReport aggregates the workouts of a report. Volume is sets×reps×weight
+// and comes from the logged sets when an entry has them. Density is volume
+// per minute, averaged over the sessions that have both a start and a
+// completion time.
+func BuildReport(q ReportQuery, workouts []ReportWorkout) *Report {
+	report := &Report{
+		StartDate:        q.Start.Format("2006-01-02"),
+		EndDate:          q.End.Format("2006-01-02"),
+		Status:           q.Status,
+		GroupBy:          q.GroupBy,
+		VolumeByExercise: make([]ExerciseVolume, 0),
+		VolumeByCategory: make([]CategoryVolume, 0),
+		MuscleGroupSets:  make([]MuscleGroupSets, 0),
+		Cardio:           make([]CardioSummary, 0),
+		BestSets:         make([]BestSet, 0),
+		Workouts:         workouts,
+	}
+	if report.Workouts == nil {
+		report.Workouts = make([]ReportWorkout, 0)
+	}
+
+	byExercise := make(map[int]*ExerciseVolume)
+	byCategory := make(map[string]*CategoryVolume)
+	best := make(map[int]*BestSet)
+	cardio := make(map[int]*CardioSummary)
+	type muscleWeek struct {
+		week   time.Time
+		muscle string
+	}
+	byMuscle := make(map[muscleWeek]*MuscleGroupSets)
+	muscleSets := func(week time.Time, muscle string) *MuscleGroupSets {
+		key := muscleWeek{week, muscle}
+		ms, ok := byMuscle[key]
+		if !ok {
+			ms = &MuscleGroupSets{Week: week, MuscleGroup: muscle}
+			byMuscle[key] = ms
+		}
+		return ms
+	}
+	buckets := make(map[time.Time]*ReportBucket)
+
+	var densitySum float64
+	var densityCount int
+
+	for i := range report.Workouts {
+		workout := &report.Workouts[i]
+		workout.Volume = 0
+
+		var workoutSets int
+		week := PeriodStart(workout.ScheduledFor, IntervalWeek)
+		for j := range workout.Exercises {
+			entry := &workout.Exercises[j]
+			entry.Volume = entry.WorkoutExercise.Volume()
+			performed := entry.PerformedSets(true)
+
+			workout.Volume += entry.Volume
+			workoutSets += len(performed)
+			report.TotalExercises++
+
+			ev, ok := byExercise[entry.ExerciseID]
+			if !ok {
+				ev = &ExerciseVolume{ExerciseID: entry.ExerciseID, ExerciseName: entry.ExerciseName}
+				byExercise[entry.ExerciseID] = ev
+			}
+			ev.Sets += len(performed)
+			ev.Volume += entry.Volume
+
+			cv, ok := byCategory[entry.Category]
+			if !ok {
+				cv = &CategoryVolume{Category: entry.Category}
+				byCategory[entry.Category] = cv
+			}
+			cv.Sets += len(performed)
+			cv.Volume += entry.Volume
+
+			if distance, duration, pacedDistance, pacedDuration := entry.Cardio(); distance > 0 || duration > 0 {
+				cs, ok := cardio[entry.ExerciseID]
+				if !ok {
+					cs = &CardioSummary{ExerciseID: entry.ExerciseID, ExerciseName: entry.ExerciseName}
+					cardio[entry.ExerciseID] = cs
+				}
+				cs.Distance += distance
+				cs.Duration += duration
+				cs.pacedDistance += pacedDistance
+				cs.pacedDuration += pacedDuration
+				report.TotalDistance += distance
+				report.TotalDuration += duration
+			}
+
+			if len(performed) > 0 {
+				for _, m := range entry.PrimaryMuscles {
+					muscleSets(week, m).Sets += len(performed)
+				}
+				for _, m := range entry.SecondaryMuscles {
+					muscleSets(week, m).SecondarySets += len(performed)
+				}
+			}
+
+			for _, s := range performed {
+				current := best[entry.ExerciseID]
+				if s.Weight <= 0 {
+					continue
+				}
+				if current == nil || s.Weight > current.Weight || (s.Weight == current.Weight && s.Reps > current.Reps) {
+					best[entry.ExerciseID] = &BestSet{
+						ExerciseID:         entry.ExerciseID,
+						ExerciseName:       entry.ExerciseName,
+						WorkoutID:          workout.ID,
+						Date:               workout.ScheduledFor,
+						Weight:             s.Weight,
+						Reps:               s.Reps,
+						EstimatedOneRepMax: FormulaEpley.Estimate(s.Weight, s.Reps),
+					}
+				}
+			}
+		}
+
+		report.TotalWorkouts++
+		report.TotalSets += workoutSets
+		report.TotalVolume += workout.Volume
+
+		if workout.StartedAt != nil && workout.CompletedAt != nil {
+			if minutes := workout.CompletedAt.Sub(*workout.StartedAt).Minutes(); minutes > 0 {
+				densitySum += workout.Volume / minutes
+				densityCount++
+			}
+		}
+
+		if q.GroupBy != "" {
+			period := PeriodStart(workout.ScheduledFor, q.GroupBy)
+			bucket, ok := buckets[period]
+			if !ok {
+				bucket = &ReportBucket{Period: period}
+				buckets[period] = bucket
+			}
+			bucket.Workouts++
+			bucket.Sets += workoutSets
+			bucket.Volume += workout.Volume
+		}
+	}
+
+	days := q.End.Sub(q.Start).Hours()/24 + 1
+	if weeks := math.Ceil(days) / 7; weeks > 0 {
+		report.SessionsPerWeek = round2(float64(report.TotalWorkouts) / weeks)
+	}
+	if densityCount > 0 {
+		report.AverageDensity = round2(densitySum / float64(densityCount))
+	}
+
+	for _, ev := range byExercise {
+		report.VolumeByExercise = append(report.VolumeByExercise, *ev)
+	}
+	sort.Slice(report.VolumeByExercise, func(i, j int) bool {
+		a, b := report.VolumeByExercise[i], report.VolumeByExercise[j]
+		return a.Volume > b.Volume || (a.Volume == b.Volume && a.ExerciseID < b.ExerciseID)
+	})
+
+	for _, cv := range byCategory {
+		report.VolumeByCategory = append(report.VolumeByCategory, *cv)
+	}
+	sort.Slice(report.VolumeByCategory, func(i, j int) bool {
+		a, b := report.VolumeByCategory[i], report.VolumeByCategory[j]
+		return a.Volume > b.Volume || (a.Volume == b.Volume && a.Category < b.Category)
+	})
+
+	for _, cs := range cardio {
+		cs.Pace = Pace(cs.pacedDistance, cs.pacedDuration)
+		report.Cardio = append(report.Cardio, *cs)
+	}
+	sort.Slice(report.Cardio, func(i, j int) bool {
+		return report.Cardio[i].ExerciseID < report.Cardio[j].ExerciseID
+	})
+
+	for _, ms := range byMuscle {
+		report.MuscleGroupSets = append(report.MuscleGroupSets, *ms)
+	}
+	sort.Slice(report.MuscleGroupSets, func(i, j int) bool {
+		a, b := report.MuscleGroupSets[i], report.MuscleGroupSets[j]
+		return a.Week.Before(b.Week) || (a.Week.Equal(b.Week) && a.MuscleGroup < b.MuscleGroup)
+	})
+
+	for _, bs := range best {
+		report.BestSets = append(report.BestSets, *bs)
+	}
+	sort.Slice(report.BestSets, func(i, j int) bool {
+		return report.BestSets[i].ExerciseID < report.BestSets[j].ExerciseID
+	})
+
+	for _, bucket := range buckets {
+		report.Buckets = append(report.Buckets, *bucket)
+	}
+	sort.Slice(report.Buckets, func(i, j int) bool {
+		return report.Buckets[i].Period.Before(report.Buckets[j].Period)
+	})
+
+	return report
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
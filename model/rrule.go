@@ -0,0 +1,299 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+)
+
+var ErrInvalidRRule = errors.New("invalid rrule")
+
+// maxRRulePeriods stops a rule that never matches from looping forever.
+const maxRRulePeriods = 100000
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// RRule is the subset of an RFC 5545 recurrence rule we support: FREQ
+// (DAILY, WEEKLY or MONTHLY), INTERVAL, BYDAY (plain weekdays), BYMONTHDAY,
+// COUNT and UNTIL. Weeks start on Monday.
+type RRule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Count      int
+	Until      *time.Time
+}
+
+// ParseRRule reads a rule such as "FREQ=WEEKLY;BYDAY=MO,WE,FR". A date-only
+// UNTIL is taken as the end of that day in loc.
+func ParseRRule(s string, loc *time.Location) (*RRule, error) {
+	rule := &RRule{Interval: 1}
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRRule, part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(value))
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRRule)
+			}
+			rule.Interval = n
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := weekdayCodes[strings.ToUpper(code)]
+				if !ok {
+					return nil, fmt.Errorf("%w: unsupported BYDAY value %q", ErrInvalidRRule, code)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("%w: invalid BYMONTHDAY value %q", ErrInvalidRRule, v)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be a positive number", ErrInvalidRRule)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value, loc)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		case "WKST":
+			if strings.ToUpper(value) != "MO" {
+				return nil, fmt.Errorf("%w: only WKST=MO is supported", ErrInvalidRRule)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %q", ErrInvalidRRule, key)
+		}
+	}
+
+	switch rule.Freq {
+	case FreqDaily, FreqWeekly, FreqMonthly:
+	case "":
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRRule)
+	default:
+		return nil, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRRule, rule.Freq)
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL cannot both be set", ErrInvalidRRule)
+	}
+
+	sort.Slice(rule.ByDay, func(i, j int) bool {
+		return mondayFirst(rule.ByDay[i]) < mondayFirst(rule.ByDay[j])
+	})
+
+	return rule, nil
+}
+
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102", value, loc); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("%w: invalid UNTIL %q", ErrInvalidRRule, value)
+}
+
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = strings.ToUpper(day.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrences returns the times the rule produces after `after` and up to
+// and including `before`, for a series whose first possible occurrence is
+// start. Each occurrence has start's wall-clock time in start's location,
+// so it stays at 07:00 across DST changes. Only times matching the rule
+// count: a start that does not match is not itself an occurrence.
+func (r *RRule) Occurrences(start, after, before time.Time) []time.Time {
+	var occurrences []time.Time
+	loc := start.Location()
+	hour, min, sec := start.Clock()
+	count := 0
+
+	for p := 0; p < maxRRulePeriods; p++ {
+		periodStart, days := r.period(start, p)
+		if periodStart.After(before) || (r.Until != nil && periodStart.After(*r.Until)) {
+			break
+		}
+
+		for _, day := range days {
+			t := time.Date(day.Year(), day.Month(), day.Day(), hour, min, sec, 0, loc)
+			if t.Before(start) {
+				continue
+			}
+
+			count++
+			if (r.Count > 0 && count > r.Count) || (r.Until != nil && t.After(*r.Until)) || t.After(before) {
+				return occurrences
+			}
+			if t.After(after) {
+				occurrences = append(occurrences, t)
+			}
+		}
+	}
+
+	return occurrences
+}
+
+// period returns the first day of the p-th period of the rule and the days
+// in it that match, in order.
+func (r *RRule) period(start time.Time, p int) (time.Time, []time.Time) {
+	loc := start.Location()
+	y, m, d := start.Date()
+
+	switch r.Freq {
+	case FreqDaily:
+		day := time.Date(y, m, d+p*r.Interval, 0, 0, 0, 0, loc)
+		if r.matchesDay(day) && r.matchesMonthDay(day) {
+			return day, []time.Time{day}
+		}
+		return day, nil
+
+	case FreqWeekly:
+		monday := time.Date(y, m, d-mondayFirst(start.Weekday())+p*7*r.Interval, 0, 0, 0, 0, loc)
+		weekdays := r.ByDay
+		if len(weekdays) == 0 {
+			weekdays = []time.Weekday{start.Weekday()}
+		}
+		var days []time.Time
+		for _, wd := range weekdays {
+			day := monday.AddDate(0, 0, mondayFirst(wd))
+			if r.matchesMonthDay(day) {
+				days = append(days, day)
+			}
+		}
+		return monday, days
+
+	default:
+		first := time.Date(y, m+time.Month(p*r.Interval), 1, 0, 0, 0, 0, loc)
+		length := first.AddDate(0, 1, -1).Day()
+
+		var days []time.Time
+		switch {
+		case len(r.ByMonthDay) > 0:
+			seen := make(map[int]bool)
+			var monthDays []int
+			for _, md := range r.ByMonthDay {
+				if md < 0 {
+					md = length + md + 1
+				}
+				if md >= 1 && md <= length && !seen[md] {
+					seen[md] = true
+					monthDays = append(monthDays, md)
+				}
+			}
+			sort.Ints(monthDays)
+			for _, md := range monthDays {
+				day := first.AddDate(0, 0, md-1)
+				if r.matchesDay(day) {
+					days = append(days, day)
+				}
+			}
+		case len(r.ByDay) > 0:
+			for md := 1; md <= length; md++ {
+				day := first.AddDate(0, 0, md-1)
+				if r.matchesDay(day) {
+					days = append(days, day)
+				}
+			}
+		default:
+			if d <= length {
+				days = append(days, first.AddDate(0, 0, d-1))
+			}
+		}
+		return first, days
+	}
+}
+
+func (r *RRule) matchesDay(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if day.Weekday() == wd {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RRule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	length := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+	for _, md := range r.ByMonthDay {
+		if md < 0 {
+			md = length + md + 1
+		}
+		if day.Day() == md {
+			return true
+		}
+	}
+	return false
+}
+
+// mondayFirst numbers weekdays from Monday (0) to Sunday (6).
+func mondayFirst(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
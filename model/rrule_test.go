@@ -0,0 +1,255 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		want string
+	}{
+		{"weekly by day", "FREQ=WEEKLY;BYDAY=MO,WE,FR", "FREQ=WEEKLY;BYDAY=MO,WE,FR"},
+		{"days sorted from Monday", "FREQ=WEEKLY;BYDAY=SU,FR,MO", "FREQ=WEEKLY;BYDAY=MO,FR,SU"},
+		{"prefix and lower case", "RRULE:freq=daily;interval=2;count=5", "FREQ=DAILY;INTERVAL=2;COUNT=5"},
+		{"interval of one left out", "FREQ=DAILY;INTERVAL=1", "FREQ=DAILY"},
+		{"month days", "FREQ=MONTHLY;BYMONTHDAY=1,15,-1", "FREQ=MONTHLY;BYMONTHDAY=1,15,-1"},
+		{"date-only until ends the day", "FREQ=DAILY;UNTIL=20261231", "FREQ=DAILY;UNTIL=20261231T235959Z"},
+		{"utc until", "FREQ=WEEKLY;UNTIL=20261231T070000Z", "FREQ=WEEKLY;UNTIL=20261231T070000Z"},
+		{"monday week start", "FREQ=WEEKLY;WKST=MO", "FREQ=WEEKLY"},
+		{"trailing separator", "FREQ=DAILY;", "FREQ=DAILY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule, time.UTC)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) error = %v", tt.rule, err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("ParseRRule(%q).String() = %q, want %q", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRRuleUntilInLocation(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	rule, err := ParseRRule("FREQ=DAILY;UNTIL=20261231", berlin)
+	if err != nil {
+		t.Fatalf("ParseRRule error = %v", err)
+	}
+	want := time.Date(2026, 12, 31, 23, 59, 59, 0, berlin)
+	if !rule.Until.Equal(want) {
+		t.Errorf("Until = %v, want %v", rule.Until, want)
+	}
+}
+
+func TestParseRRuleInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+	}{
+		{"empty", ""},
+		{"no freq", "BYDAY=MO"},
+		{"yearly", "FREQ=YEARLY"},
+		{"missing value", "FREQ"},
+		{"zero interval", "FREQ=DAILY;INTERVAL=0"},
+		{"ordinal weekday", "FREQ=MONTHLY;BYDAY=1MO"},
+		{"month day out of range", "FREQ=MONTHLY;BYMONTHDAY=32"},
+		{"zero month day", "FREQ=MONTHLY;BYMONTHDAY=0"},
+		{"zero count", "FREQ=DAILY;COUNT=0"},
+		{"count and until", "FREQ=DAILY;COUNT=2;UNTIL=20261231"},
+		{"bad until", "FREQ=DAILY;UNTIL=2026-12-31"},
+		{"sunday week start", "FREQ=WEEKLY;WKST=SU"},
+		{"unsupported part", "FREQ=DAILY;BYHOUR=7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRRule(tt.rule, time.UTC)
+			if !errors.Is(err, ErrInvalidRRule) {
+				t.Errorf("ParseRRule(%q) error = %v, want %v", tt.rule, err, ErrInvalidRRule)
+			}
+		})
+	}
+}
+
+func TestRRuleOccurrences(t *testing.T) {
+	at7 := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 7, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		rule   string
+		start  time.Time
+		after  time.Time
+		before time.Time
+		want   []time.Time
+	}{
+		{
+			name:   "weekly by day",
+			rule:   "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			start:  at7(2026, 10, 19),
+			after:  at7(2026, 10, 18),
+			before: at7(2026, 10, 26),
+			want:   []time.Time{at7(2026, 10, 19), at7(2026, 10, 21), at7(2026, 10, 23), at7(2026, 10, 26)},
+		},
+		{
+			name:   "start that does not match is skipped",
+			rule:   "FREQ=WEEKLY;BYDAY=MO",
+			start:  at7(2026, 10, 20),
+			after:  at7(2026, 10, 1),
+			before: at7(2026, 11, 3),
+			want:   []time.Time{at7(2026, 10, 26), at7(2026, 11, 2)},
+		},
+		{
+			name:   "weekly defaults to the start's weekday",
+			rule:   "FREQ=WEEKLY",
+			start:  at7(2026, 10, 21),
+			after:  at7(2026, 10, 1),
+			before: at7(2026, 11, 4),
+			want:   []time.Time{at7(2026, 10, 21), at7(2026, 10, 28), at7(2026, 11, 4)},
+		},
+		{
+			name:   "every other week",
+			rule:   "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO",
+			start:  at7(2026, 10, 19),
+			after:  at7(2026, 10, 1),
+			before: at7(2026, 11, 30),
+			want:   []time.Time{at7(2026, 10, 19), at7(2026, 11, 2), at7(2026, 11, 16), at7(2026, 11, 30)},
+		},
+		{
+			name:   "after is exclusive",
+			rule:   "FREQ=DAILY",
+			start:  at7(2026, 10, 19),
+			after:  at7(2026, 10, 19),
+			before: at7(2026, 10, 21),
+			want:   []time.Time{at7(2026, 10, 20), at7(2026, 10, 21)},
+		},
+		{
+			name:   "count stops the series",
+			rule:   "FREQ=DAILY;COUNT=3",
+			start:  at7(2026, 10, 19),
+			after:  at7(2026, 10, 1),
+			before: at7(2026, 12, 31),
+			want:   []time.Time{at7(2026, 10, 19), at7(2026, 10, 20), at7(2026, 10, 21)},
+		},
+		{
+			name:   "count includes occurrences before after",
+			rule:   "FREQ=DAILY;COUNT=3",
+			start:  at7(2026, 10, 19),
+			after:  at7(2026, 10, 20),
+			before: at7(2026, 12, 31),
+			want:   []time.Time{at7(2026, 10, 21)},
+		},
+		{
+			name:   "until stops the series",
+			rule:   "FREQ=DAILY;INTERVAL=2;UNTIL=20261024",
+			start:  at7(2026, 10, 19),
+			after:  at7(2026, 10, 1),
+			before: at7(2026, 12, 31),
+			want:   []time.Time{at7(2026, 10, 19), at7(2026, 10, 21), at7(2026, 10, 23)},
+		},
+		{
+			name:   "daily by day",
+			rule:   "FREQ=DAILY;BYDAY=SA,SU",
+			start:  at7(2026, 10, 19),
+			after:  at7(2026, 10, 1),
+			before: at7(2026, 11, 1),
+			want:   []time.Time{at7(2026, 10, 24), at7(2026, 10, 25), at7(2026, 10, 31), at7(2026, 11, 1)},
+		},
+		{
+			name:   "last day of the month",
+			rule:   "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start:  at7(2026, 1, 1),
+			after:  at7(2025, 12, 1),
+			before: at7(2026, 4, 1),
+			want:   []time.Time{at7(2026, 1, 31), at7(2026, 2, 28), at7(2026, 3, 31)},
+		},
+		{
+			name:   "monthly on the 31st skips short months",
+			rule:   "FREQ=MONTHLY",
+			start:  at7(2026, 1, 31),
+			after:  at7(2025, 12, 1),
+			before: at7(2026, 5, 31),
+			want:   []time.Time{at7(2026, 1, 31), at7(2026, 3, 31), at7(2026, 5, 31)},
+		},
+		{
+			name:   "monthly by weekday",
+			rule:   "FREQ=MONTHLY;BYDAY=MO;BYMONTHDAY=1,2,3,4,5,6,7",
+			start:  at7(2026, 10, 1),
+			after:  at7(2026, 9, 1),
+			before: at7(2026, 12, 31),
+			want:   []time.Time{at7(2026, 10, 5), at7(2026, 11, 2), at7(2026, 12, 7)},
+		},
+		{
+			name:   "nothing before the start",
+			rule:   "FREQ=DAILY",
+			start:  at7(2026, 10, 19),
+			after:  at7(2026, 10, 1),
+			before: at7(2026, 10, 18),
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule, time.UTC)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) error = %v", tt.rule, err)
+			}
+			got := rule.Occurrences(tt.start, tt.after, tt.before)
+			assertTimes(t, got, tt.want)
+		})
+	}
+}
+
+func TestRRuleOccurrencesKeepWallClockAcrossDST(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	rule, err := ParseRRule("FREQ=DAILY", berlin)
+	if err != nil {
+		t.Fatalf("ParseRRule error = %v", err)
+	}
+
+	// Summer time ends in the night to 25 October 2026.
+	start := time.Date(2026, 10, 24, 7, 0, 0, 0, berlin)
+	got := rule.Occurrences(start, start.Add(-time.Second), start.AddDate(0, 0, 2))
+	want := []time.Time{
+		time.Date(2026, 10, 24, 7, 0, 0, 0, berlin),
+		time.Date(2026, 10, 25, 7, 0, 0, 0, berlin),
+		time.Date(2026, 10, 26, 7, 0, 0, 0, berlin),
+	}
+	assertTimes(t, got, want)
+
+	if got[0].Sub(got[1]) == got[1].Sub(got[2]) {
+		t.Errorf("occurrences are evenly spaced across the DST change, want a 25 hour day")
+	}
+}
+
+func assertTimes(t *testing.T, got, want []time.Time) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d occurrences %v, want %d %v", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("occurrence %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	return loc
+}
@@ -0,0 +1,184 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ScheduleHorizon is how far ahead workouts are generated for a schedule.
+const ScheduleHorizon = 28 * 24 * time.Hour
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule creates a workout for every occurrence of a recurrence rule,
+// from an optional template. StartsAt holds the first possible occurrence;
+// its wall-clock time in TimeZone is the time of every workout. Exceptions
+// are local dates (YYYY-MM-DD) that are left out.
+type Schedule struct {
+	ID             int                `json:"id"`
+	UserID         int                `json:"user_id"`
+	TemplateID     *int               `json:"template_id"`
+	Name           string             `json:"name"`
+	Description    string             `json:"description"`
+	TimeZone       string             `json:"timezone"`
+	StartsAt       time.Time          `json:"starts_at"`
+	RRule          string             `json:"rrule"`
+	Exceptions     []string           `json:"exceptions"`
+	GeneratedUntil time.Time          `json:"generated_until"`
+	Workouts       []ScheduledWorkout `json:"workouts,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+// ScheduledWorkout links a generated workout to the occurrence it was made
+// for. A detached workout was edited on its own and is left alone when the
+// rest of the series changes.
+type ScheduledWorkout struct {
+	WorkoutID  int       `json:"workout_id"`
+	Occurrence time.Time `json:"occurrence"`
+	Detached   bool      `json:"detached"`
+}
+
+func NewSchedule(userID int, name, description string, templateID *int, timeZone string, startsAt time.Time, rrule string, exceptions []string) (*Schedule, error) {
+	s := &Schedule{
+		UserID:      userID,
+		TemplateID:  templateID,
+		Name:        name,
+		Description: description,
+		TimeZone:    timeZone,
+		StartsAt:    startsAt,
+		RRule:       rrule,
+		Exceptions:  exceptions,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if s.Exceptions == nil {
+		s.Exceptions = []string{}
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate checks the schedule and normalizes its rule.
+func (s *Schedule) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidSchedule)
+	}
+
+	rule, loc, err := s.Rule()
+	if err != nil {
+		return err
+	}
+	s.RRule = rule.String()
+	s.StartsAt = s.StartsAt.In(loc)
+
+	for _, date := range s.Exceptions {
+		if _, err := time.ParseInLocation(time.DateOnly, date, loc); err != nil {
+			return fmt.Errorf("%w: exception %q is not a YYYY-MM-DD date", ErrInvalidSchedule, date)
+		}
+	}
+
+	return nil
+}
+
+// Rule parses the schedule's time zone and recurrence rule.
+func (s *Schedule) Rule() (*RRule, *time.Location, error) {
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidSchedule, s.TimeZone)
+	}
+
+	rule, err := ParseRRule(s.RRule, loc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rule, loc, nil
+}
+
+// Occurrences returns the schedule's occurrences after `after` and up to
+// `before`, leaving out exception dates.
+func (s *Schedule) Occurrences(after, before time.Time) ([]time.Time, error) {
+	rule, loc, err := s.Rule()
+	if err != nil {
+		return nil, err
+	}
+
+	var occurrences []time.Time
+	for _, t := range rule.Occurrences(s.StartsAt.In(loc), after, before) {
+		if !s.IsException(t) {
+			occurrences = append(occurrences, t)
+		}
+	}
+	return occurrences, nil
+}
+
+// IsException reports whether t falls on one of the exception dates in the
+// schedule's time zone.
+func (s *Schedule) IsException(t time.Time) bool {
+	if loc, err := time.LoadLocation(s.TimeZone); err == nil {
+		t = t.In(loc)
+	}
+	date := t.Format(time.DateOnly)
+	for _, e := range s.Exceptions {
+		if e == date {
+			return true
+		}
+	}
+	return false
+}
+
+// AddException leaves the given local date out of the series.
+func (s *Schedule) AddException(date string) error {
+	if _, err := time.Parse(time.DateOnly, date); err != nil {
+		return fmt.Errorf("%w: exception %q is not a YYYY-MM-DD date", ErrInvalidSchedule, date)
+	}
+	for _, e := range s.Exceptions {
+		if e == date {
+			return nil
+		}
+	}
+	s.Exceptions = append(s.Exceptions, date)
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// Split ends the schedule just before occurrence and returns a copy that
+// continues the series from there, so "this and all future" edits leave
+// earlier workouts alone. A COUNT limit is shared between the two parts.
+func (s *Schedule) Split(occurrence time.Time) (*Schedule, error) {
+	rule, loc, err := s.Rule()
+	if err != nil {
+		return nil, err
+	}
+
+	next := *s
+	next.ID = 0
+	next.Workouts = nil
+	next.Exceptions = append([]string{}, s.Exceptions...)
+	next.StartsAt = occurrence.In(loc)
+	next.GeneratedUntil = time.Time{}
+	next.CreatedAt = time.Now()
+	next.UpdatedAt = time.Now()
+
+	nextRule := *rule
+	if rule.Count > 0 {
+		done := len(rule.Occurrences(s.StartsAt.In(loc), s.StartsAt.Add(-time.Second), occurrence.Add(-time.Second)))
+		nextRule.Count = rule.Count - done
+		if nextRule.Count < 1 {
+			return nil, fmt.Errorf("%w: the series has no occurrences left", ErrInvalidSchedule)
+		}
+	}
+	next.RRule = nextRule.String()
+
+	until := occurrence.Add(-time.Second).UTC()
+	rule.Count = 0
+	rule.Until = &until
+	s.RRule = rule.String()
+	s.UpdatedAt = time.Now()
+
+	return &next, nil
+}
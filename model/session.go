@@ -0,0 +1,70 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// Session groups every refresh token issued from a single login. Revoking the
+// session revokes the whole token family and any access token tied to it.
+type Session struct {
+	ID        string     `json:"id"`
+	UserID    int        `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type RefreshToken struct {
+	ID        int        `json:"id"`
+	SessionID string     `json:"session_id"`
+	UserID    int        `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func NewSession(userID int) (*Session, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		ID:        hex.EncodeToString(id),
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// NewRefreshToken returns the token record to persist along with the plain
+// token that is handed to the client. Only the hash is ever stored.
+func NewRefreshToken(sessionID string, userID int) (*RefreshToken, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	return &RefreshToken{
+		SessionID: sessionID,
+		UserID:    userID,
+		TokenHash: HashRefreshToken(token),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+		CreatedAt: time.Now(),
+	}, token, nil
+}
+
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (t *RefreshToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
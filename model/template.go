@@ -0,0 +1,78 @@
+package model
+
+import "time"
+
+type WorkoutTemplate struct {
+	ID          int                `json:"id"`
+	UserID      int                `json:"user_id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Exercises   []TemplateExercise `json:"exercises"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+type TemplateExercise struct {
+	ID           int     `json:"id"`
+	TemplateID   int     `json:"template_id"`
+	ExerciseID   int     `json:"exercise_id"`
+	Position     int     `json:"position"`
+	TargetSets   int     `json:"target_sets"`
+	TargetReps   int     `json:"target_reps"`
+	TargetWeight float64 `json:"target_weight"`
+	Notes        string  `json:"notes"`
+}
+
+func NewWorkoutTemplate(userID int, name, description string) *WorkoutTemplate {
+	return &WorkoutTemplate{
+		UserID:      userID,
+		Name:        name,
+		Description: description,
+		Exercises:   make([]TemplateExercise, 0),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+// NewTemplateFromWorkout copies a workout's exercises, in order, as targets.
+func NewTemplateFromWorkout(workout *Workout, name string) *WorkoutTemplate {
+	if name == "" {
+		name = workout.Name
+	}
+
+	template := NewWorkoutTemplate(workout.UserID, name, workout.Description)
+	for _, e := range workout.Exercises {
+		template.AddExercise(e.ExerciseID, e.Sets, e.Reps, e.Weight, e.Notes)
+	}
+	return template
+}
+
+// AddExercise appends an exercise to the end of the template.
+func (t *WorkoutTemplate) AddExercise(exerciseID, sets, reps int, weight float64, notes string) {
+	t.Exercises = append(t.Exercises, TemplateExercise{
+		ExerciseID:   exerciseID,
+		Position:     len(t.Exercises) + 1,
+		TargetSets:   sets,
+		TargetReps:   reps,
+		TargetWeight: weight,
+		Notes:        notes,
+	})
+}
+
+// Instantiate builds a concrete workout for the given date from the template.
+func (t *WorkoutTemplate) Instantiate(scheduledFor time.Time) *Workout {
+	workout := NewWorkout(t.UserID, t.Name, t.Description, scheduledFor)
+	for _, e := range t.Exercises {
+		workout.AddExercise(e.ExerciseID, e.TargetSets, e.TargetReps, e.TargetWeight, e.Notes)
+	}
+	return workout
+}
+
+// ExerciseIDs lists the exercises the template uses.
+func (t *WorkoutTemplate) ExerciseIDs() []int {
+	ids := make([]int, len(t.Exercises))
+	for i, e := range t.Exercises {
+		ids[i] = e.ExerciseID
+	}
+	return ids
+}
@@ -1,18 +1,30 @@
 package model
 
 import (
+	"errors"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// AccountDeletionGracePeriod is how long a user has to change their mind
+// after asking for their account to be deleted.
+const AccountDeletionGracePeriod = 14 * 24 * time.Hour
+
+var (
+	ErrDeletionPending    = errors.New("account deletion is already scheduled")
+	ErrDeletionNotPending = errors.New("account deletion is not scheduled")
+)
+
 type User struct {
-	ID           int       `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                  int        `json:"id"`
+	Username            string     `json:"username"`
+	Email               string     `json:"email"`
+	PasswordHash        string     `json:"-"`
+	IsAdmin             bool       `json:"is_admin"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 func NewUser(username, email, password string) (*User, error) {
@@ -34,3 +46,24 @@ func (u *User) CheckPassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
 	return err == nil
 }
+
+// ScheduleDeletion marks the account to be deleted once the grace period
+// is over.
+func (u *User) ScheduleDeletion() error {
+	if u.DeletionScheduledAt != nil {
+		return ErrDeletionPending
+	}
+	at := time.Now().Add(AccountDeletionGracePeriod)
+	u.DeletionScheduledAt = &at
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+func (u *User) CancelDeletion() error {
+	if u.DeletionScheduledAt == nil {
+		return ErrDeletionNotPending
+	}
+	u.DeletionScheduledAt = nil
+	u.UpdatedAt = time.Now()
+	return nil
+}
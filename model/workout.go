@@ -1,6 +1,36 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+type WorkoutStatus string
+
+const (
+	StatusScheduled  WorkoutStatus = "scheduled"
+	StatusInProgress WorkoutStatus = "in_progress"
+	StatusCompleted  WorkoutStatus = "completed"
+	StatusSkipped    WorkoutStatus = "skipped"
+)
+
+const (
+	SetTypeWarmup  = "warmup"
+	SetTypeWorking = "working"
+	SetTypeDrop    = "drop"
+	SetTypeFailure = "failure"
+)
+
+var (
+	ErrInvalidStatus     = errors.New("status must be one of scheduled, in_progress, completed, skipped")
+	ErrInvalidTransition = errors.New("workout cannot make that status transition")
+	ErrInvalidSetType    = errors.New("set_type must be one of warmup, working, drop, failure")
+	ErrInvalidRPE        = errors.New("rpe must be between 1 and 10")
+	ErrInvalidRIR        = errors.New("rir must be between 0 and 10")
+	ErrInvalidTempo      = errors.New("tempo must have four phases of digits or X, e.g. 3-1-X-0")
+	ErrInvalidSet        = errors.New("reps, weight, distance and duration must not be negative")
+)
 
 type Workout struct {
 	ID           int               `json:"id"`
@@ -8,19 +38,45 @@ type Workout struct {
 	Name         string            `json:"name"`
 	Description  string            `json:"description"`
 	ScheduledFor time.Time         `json:"scheduled_for"`
+	Status       WorkoutStatus     `json:"status"`
+	StartedAt    *time.Time        `json:"started_at,omitempty"`
+	CompletedAt  *time.Time        `json:"completed_at,omitempty"`
 	Exercises    []WorkoutExercise `json:"exercises"`
+	Groups       []ExerciseGroup   `json:"groups"`
 	CreatedAt    time.Time         `json:"created_at"`
 	UpdatedAt    time.Time         `json:"updated_at"`
 }
 
 type WorkoutExercise struct {
-	ID         int     `json:"id"`
-	WorkoutID  int     `json:"workout_id"`
-	ExerciseID int     `json:"exercise_id"`
-	Sets       int     `json:"sets"`
-	Reps       int     `json:"reps"`
-	Weight     float64 `json:"weight"`
-	Notes      string  `json:"notes"`
+	ID         int          `json:"id"`
+	WorkoutID  int          `json:"workout_id"`
+	ExerciseID int          `json:"exercise_id"`
+	Position   int          `json:"position"`
+	Group      string       `json:"group,omitempty"`
+	Sets       int          `json:"sets"`
+	Reps       int          `json:"reps"`
+	Weight     float64      `json:"weight"`
+	Distance   float64      `json:"distance"`
+	Duration   int          `json:"duration"`
+	Notes      string       `json:"notes"`
+	SetLog     []WorkoutSet `json:"set_log"`
+	Records    []RecordType `json:"records,omitempty"`
+}
+
+// WorkoutSet is a single logged set of a workout exercise.
+type WorkoutSet struct {
+	ID                int      `json:"id"`
+	WorkoutExerciseID int      `json:"workout_exercise_id"`
+	SetNumber         int      `json:"set_number"`
+	Reps              int      `json:"reps"`
+	Weight            float64  `json:"weight"`
+	Distance          float64  `json:"distance"`
+	Duration          int      `json:"duration"`
+	RPE               *float64 `json:"rpe,omitempty"`
+	RIR               *int     `json:"rir,omitempty"`
+	Tempo             string   `json:"tempo,omitempty"`
+	SetType           string   `json:"set_type"`
+	Completed         bool     `json:"completed"`
 }
 
 func NewWorkout(userID int, name, description string, scheduledFor time.Time) *Workout {
@@ -29,18 +85,243 @@ func NewWorkout(userID int, name, description string, scheduledFor time.Time) *W
 		Name:         name,
 		Description:  description,
 		ScheduledFor: scheduledFor,
+		Status:       StatusScheduled,
 		Exercises:    make([]WorkoutExercise, 0),
+		Groups:       make([]ExerciseGroup, 0),
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 }
 
-func (w *Workout) AddExercise(exerciseID, sets, reps int, weight float64, notes string) {
+func (w *Workout) AddExercise(exerciseID, sets, reps int, weight float64, notes string) *WorkoutExercise {
 	w.Exercises = append(w.Exercises, WorkoutExercise{
 		ExerciseID: exerciseID,
+		Position:   len(w.Exercises) + 1,
 		Sets:       sets,
 		Reps:       reps,
 		Weight:     weight,
 		Notes:      notes,
 	})
+	return &w.Exercises[len(w.Exercises)-1]
+}
+
+// InsertExercise adds an entry at a position counted from 1 and shifts the
+// entries from there on down. Without a position, or past the end, the
+// entry is added last.
+func (w *Workout) InsertExercise(position int, entry WorkoutExercise) *WorkoutExercise {
+	i := position - 1
+	if i < 0 || i > len(w.Exercises) {
+		i = len(w.Exercises)
+	}
+	w.Exercises = append(w.Exercises[:i], append([]WorkoutExercise{entry}, w.Exercises[i:]...)...)
+	for j := range w.Exercises {
+		w.Exercises[j].Position = j + 1
+	}
+	return &w.Exercises[i]
+}
+
+// MoveExercise moves an entry to a position counted from 1.
+func (w *Workout) MoveExercise(workoutExerciseID, position int) error {
+	entry := w.entry(workoutExerciseID)
+	if entry == nil {
+		return ErrUnknownEntry
+	}
+	moved := *entry
+	if err := w.RemoveExercise(workoutExerciseID); err != nil {
+		return err
+	}
+	if position > len(w.Exercises) {
+		position = len(w.Exercises) + 1
+	}
+	if position < 1 {
+		position = 1
+	}
+	w.InsertExercise(position, moved)
+	return nil
+}
+
+// RemoveExercise drops an entry, with its logged sets, from the workout.
+func (w *Workout) RemoveExercise(workoutExerciseID int) error {
+	for i := range w.Exercises {
+		if w.Exercises[i].ID == workoutExerciseID {
+			w.Exercises = append(w.Exercises[:i], w.Exercises[i+1:]...)
+			for j := range w.Exercises {
+				w.Exercises[j].Position = j + 1
+			}
+			return nil
+		}
+	}
+	return ErrUnknownEntry
+}
+
+// ExerciseIDs lists the exercises the workout uses.
+func (w *Workout) ExerciseIDs() []int {
+	ids := make([]int, len(w.Exercises))
+	for i, e := range w.Exercises {
+		ids[i] = e.ExerciseID
+	}
+	return ids
+}
+
+// ParseWorkoutStatus accepts an empty string as "any status".
+func ParseWorkoutStatus(s string) (WorkoutStatus, error) {
+	switch status := WorkoutStatus(s); status {
+	case "", StatusScheduled, StatusInProgress, StatusCompleted, StatusSkipped:
+		return status, nil
+	}
+	return "", ErrInvalidStatus
+}
+
+// Start moves a scheduled workout into progress.
+func (w *Workout) Start() error {
+	if w.Status != StatusScheduled {
+		return ErrInvalidTransition
+	}
+	now := time.Now()
+	w.Status = StatusInProgress
+	w.StartedAt = &now
+	w.UpdatedAt = now
+	return nil
+}
+
+// Finish completes a workout. A scheduled workout can be finished directly
+// when it is logged after the fact.
+func (w *Workout) Finish() error {
+	if w.Status != StatusScheduled && w.Status != StatusInProgress {
+		return ErrInvalidTransition
+	}
+	now := time.Now()
+	w.Status = StatusCompleted
+	w.CompletedAt = &now
+	w.UpdatedAt = now
+	return nil
+}
+
+// Skip marks a scheduled workout as not done.
+func (w *Workout) Skip() error {
+	if w.Status != StatusScheduled {
+		return ErrInvalidTransition
+	}
+	w.Status = StatusSkipped
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// Reopen puts a completed or skipped workout back on the schedule.
+func (w *Workout) Reopen() error {
+	if w.Status != StatusCompleted && w.Status != StatusSkipped {
+		return ErrInvalidTransition
+	}
+	w.Status = StatusScheduled
+	w.StartedAt = nil
+	w.CompletedAt = nil
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetSetLog validates and numbers the logged sets. When the entry has no
+// sets/reps/weight summary of its own, it is derived from the best working
+// set (heaviest, then most reps, farthest and longest) so the summary
+// columns stay meaningful.
+func (we *WorkoutExercise) SetSetLog(sets []WorkoutSet) error {
+	for i := range sets {
+		if sets[i].SetNumber == 0 {
+			sets[i].SetNumber = i + 1
+		}
+		if sets[i].SetType == "" {
+			sets[i].SetType = SetTypeWorking
+		}
+		if err := sets[i].Validate(); err != nil {
+			return err
+		}
+	}
+	we.SetLog = sets
+
+	if we.Sets == 0 && len(sets) > 0 {
+		for _, s := range sets {
+			if s.SetType == SetTypeWarmup {
+				continue
+			}
+			we.Sets++
+			if s.betterThan(we.Weight, we.Reps, we.Distance, we.Duration) {
+				we.Weight = s.Weight
+				we.Reps = s.Reps
+				we.Distance = s.Distance
+				we.Duration = s.Duration
+			}
+		}
+	}
+
+	return nil
+}
+
+// Volume is the load lifted for the entry. Logged sets take precedence over
+// the sets×reps×weight summary; warm-up and incomplete sets do not count.
+func (we *WorkoutExercise) Volume() float64 {
+	if len(we.SetLog) == 0 {
+		return float64(we.Sets*we.Reps) * we.Weight
+	}
+
+	var volume float64
+	for _, s := range we.SetLog {
+		if s.CountsTowardVolume() {
+			volume += float64(s.Reps) * s.Weight
+		}
+	}
+	return volume
+}
+
+func (s *WorkoutSet) Validate() error {
+	switch s.SetType {
+	case SetTypeWarmup, SetTypeWorking, SetTypeDrop, SetTypeFailure:
+	default:
+		return ErrInvalidSetType
+	}
+	if s.Reps < 0 || s.Weight < 0 || s.Distance < 0 || s.Duration < 0 {
+		return ErrInvalidSet
+	}
+	if s.RPE != nil && (*s.RPE < 1 || *s.RPE > 10) {
+		return ErrInvalidRPE
+	}
+	if s.RIR != nil && (*s.RIR < 0 || *s.RIR > 10) {
+		return ErrInvalidRIR
+	}
+	if s.Tempo != "" && !validTempo(s.Tempo) {
+		return ErrInvalidTempo
+	}
+	return nil
+}
+
+func (s *WorkoutSet) betterThan(weight float64, reps int, distance float64, duration int) bool {
+	switch {
+	case s.Weight != weight:
+		return s.Weight > weight
+	case s.Reps != reps:
+		return s.Reps > reps
+	case s.Distance != distance:
+		return s.Distance > distance
+	}
+	return s.Duration > duration
+}
+
+func (s *WorkoutSet) CountsTowardVolume() bool {
+	return s.Completed && s.SetType != SetTypeWarmup
+}
+
+// validTempo accepts the usual eccentric/pause/concentric/pause notation,
+// either compact ("31X0") or separated ("3-1-X-0").
+func validTempo(tempo string) bool {
+	phases := strings.Split(tempo, "-")
+	if len(phases) == 1 {
+		phases = strings.Split(tempo, "")
+	}
+	if len(phases) != 4 {
+		return false
+	}
+	for _, p := range phases {
+		if len(p) != 1 || !strings.ContainsAny(strings.ToUpper(p), "0123456789X") {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,144 @@
+package model
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// workoutWithEntries returns a workout whose entries have the given IDs, in
+// order.
+func workoutWithEntries(ids ...int) *Workout {
+	w := &Workout{}
+	for i, id := range ids {
+		w.Exercises = append(w.Exercises, WorkoutExercise{ID: id, ExerciseID: id * 10, Position: i + 1})
+	}
+	return w
+}
+
+// entryOrder lists the entry IDs in order and checks the positions are
+// numbered from 1 without gaps.
+func entryOrder(t *testing.T, w *Workout) []int {
+	t.Helper()
+	ids := make([]int, len(w.Exercises))
+	for i, e := range w.Exercises {
+		if e.Position != i+1 {
+			t.Errorf("entry %d has position %d, want %d", e.ID, e.Position, i+1)
+		}
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func TestWorkoutInsertExercise(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []int
+		position int
+		want     []int
+	}{
+		{"into an empty workout", nil, 0, []int{9}},
+		{"without a position", []int{1, 2, 3}, 0, []int{1, 2, 3, 9}},
+		{"first", []int{1, 2, 3}, 1, []int{9, 1, 2, 3}},
+		{"in the middle", []int{1, 2, 3}, 2, []int{1, 9, 2, 3}},
+		{"last", []int{1, 2, 3}, 4, []int{1, 2, 3, 9}},
+		{"past the end", []int{1, 2, 3}, 99, []int{1, 2, 3, 9}},
+		{"negative", []int{1, 2, 3}, -1, []int{1, 2, 3, 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := workoutWithEntries(tt.entries...)
+
+			entry := w.InsertExercise(tt.position, WorkoutExercise{ID: 9, ExerciseID: 90})
+
+			if entry.ID != 9 || entry.ExerciseID != 90 {
+				t.Errorf("InsertExercise returned entry %d of exercise %d, want 9 of 90", entry.ID, entry.ExerciseID)
+			}
+			if got := entryOrder(t, w); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkoutMoveExercise(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []int
+		id       int
+		position int
+		want     []int
+		wantErr  error
+	}{
+		{"to the front", []int{1, 2, 3}, 3, 1, []int{3, 1, 2}, nil},
+		{"to the end", []int{1, 2, 3}, 1, 3, []int{2, 3, 1}, nil},
+		{"one down", []int{1, 2, 3}, 1, 2, []int{2, 1, 3}, nil},
+		{"one up", []int{1, 2, 3}, 3, 2, []int{1, 3, 2}, nil},
+		{"to where it is", []int{1, 2, 3}, 2, 2, []int{1, 2, 3}, nil},
+		{"past the end", []int{1, 2, 3}, 1, 99, []int{2, 3, 1}, nil},
+		{"before the start", []int{1, 2, 3}, 3, 0, []int{3, 1, 2}, nil},
+		{"unknown entry", []int{1, 2, 3}, 4, 1, []int{1, 2, 3}, ErrUnknownEntry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := workoutWithEntries(tt.entries...)
+
+			err := w.MoveExercise(tt.id, tt.position)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MoveExercise(%d, %d) error = %v, want %v", tt.id, tt.position, err, tt.wantErr)
+			}
+			if got := entryOrder(t, w); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkoutMoveExerciseKeepsEntry(t *testing.T) {
+	w := workoutWithEntries(1, 2, 3)
+	w.Exercises[2].SetLog = []WorkoutSet{{SetNumber: 1, Reps: 5, Weight: 100}}
+
+	if err := w.MoveExercise(3, 1); err != nil {
+		t.Fatalf("MoveExercise error = %v", err)
+	}
+
+	moved := w.Exercises[0]
+	if moved.ID != 3 || moved.ExerciseID != 30 || len(moved.SetLog) != 1 {
+		t.Errorf("moved entry = %+v, want entry 3 of exercise 30 with its set", moved)
+	}
+}
+
+func TestWorkoutRemoveExercise(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []int
+		id      int
+		want    []int
+		wantErr error
+	}{
+		{"first", []int{1, 2, 3}, 1, []int{2, 3}, nil},
+		{"in the middle", []int{1, 2, 3}, 2, []int{1, 3}, nil},
+		{"last", []int{1, 2, 3}, 3, []int{1, 2}, nil},
+		{"only", []int{1}, 1, []int{}, nil},
+		{"unknown entry", []int{1, 2, 3}, 4, []int{1, 2, 3}, ErrUnknownEntry},
+		{"from an empty workout", nil, 1, []int{}, ErrUnknownEntry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := workoutWithEntries(tt.entries...)
+
+			err := w.RemoveExercise(tt.id)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RemoveExercise(%d) error = %v, want %v", tt.id, err, tt.wantErr)
+			}
+			if got := entryOrder(t, w); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/yeboahd24/workout-tracker/model"
+)
+
+type CalendarRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarRepository(db *sql.DB) *CalendarRepository {
+	return &CalendarRepository{db: db}
+}
+
+// Save stores the user's feed, replacing any previous token.
+func (r *CalendarRepository) Save(ctx context.Context, feed *model.CalendarFeed) error {
+	query := `
+		INSERT INTO calendar_feeds (user_id, token, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET token = EXCLUDED.token, created_at = EXCLUDED.created_at`
+
+	_, err := r.db.ExecContext(ctx, query, feed.UserID, feed.Token, feed.CreatedAt)
+	return err
+}
+
+// GetByUserID returns nil if the user has no feed yet.
+func (r *CalendarRepository) GetByUserID(ctx context.Context, userID int) (*model.CalendarFeed, error) {
+	query := `
+		SELECT user_id, token, created_at
+		FROM calendar_feeds
+		WHERE user_id = $1`
+
+	return r.scanFeed(r.db.QueryRowContext(ctx, query, userID))
+}
+
+// GetByToken returns nil if no feed has this token.
+func (r *CalendarRepository) GetByToken(ctx context.Context, token string) (*model.CalendarFeed, error) {
+	query := `
+		SELECT user_id, token, created_at
+		FROM calendar_feeds
+		WHERE token = $1`
+
+	return r.scanFeed(r.db.QueryRowContext(ctx, query, token))
+}
+
+func (r *CalendarRepository) scanFeed(row *sql.Row) (*model.CalendarFeed, error) {
+	var feed model.CalendarFeed
+	err := row.Scan(&feed.UserID, &feed.Token, &feed.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &feed, nil
+}
@@ -3,11 +3,43 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/yeboahd24/workout-tracker/model"
 )
 
+// exerciseColumns selects an exercise with its muscle groups and equipment
+// as arrays of slugs, in the order scanExercise reads them.
+const exerciseColumns = `
+	id, slug, name, description, category, measurement_type, movement_pattern, laterality, owner_user_id, promotion_status, deleted_at,
+	created_at, updated_at,
+	ARRAY(SELECT mg.slug FROM exercise_muscle_groups emg JOIN muscle_groups mg ON mg.id = emg.muscle_group_id
+		WHERE emg.exercise_id = exercises.id AND emg.role = 'primary' ORDER BY mg.slug),
+	ARRAY(SELECT mg.slug FROM exercise_muscle_groups emg JOIN muscle_groups mg ON mg.id = emg.muscle_group_id
+		WHERE emg.exercise_id = exercises.id AND emg.role = 'secondary' ORDER BY mg.slug),
+	ARRAY(SELECT eq.slug FROM exercise_equipment ee JOIN equipment eq ON eq.id = ee.equipment_id
+		WHERE ee.exercise_id = exercises.id ORDER BY eq.slug)`
 
+// scanExercise reads a row of exerciseColumns, followed by any extra
+// columns the query selects into extra.
+func scanExercise(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*model.Exercise, error) {
+	var exercise model.Exercise
+	dest := []interface{}{
+		&exercise.ID, &exercise.Slug, &exercise.Name, &exercise.Description, &exercise.Category, &exercise.MeasurementType,
+		&exercise.MovementPattern, &exercise.Laterality,
+		&exercise.OwnerUserID, &exercise.PromotionStatus, &exercise.DeletedAt,
+		&exercise.CreatedAt, &exercise.UpdatedAt,
+		pq.Array(&exercise.PrimaryMuscles), pq.Array(&exercise.SecondaryMuscles), pq.Array(&exercise.Equipment),
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &exercise, nil
+}
 
 type ExerciseRepository struct {
 	db *sql.DB
@@ -17,44 +49,177 @@ func NewExerciseRepository(db *sql.DB) *ExerciseRepository {
 	return &ExerciseRepository{db: db}
 }
 
+// Create inserts the exercise with its muscle groups and equipment. Unknown
+// slugs fail with ErrUnknownMuscleGroup or ErrUnknownEquipment.
 func (r *ExerciseRepository) Create(ctx context.Context, exercise *model.Exercise) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.CreateTx(ctx, tx, exercise); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateTx creates the exercise as part of a transaction that spans other
+// repositories.
+func (r *ExerciseRepository) CreateTx(ctx context.Context, tx *sql.Tx, exercise *model.Exercise) error {
 	query := `
-		INSERT INTO exercises (name, description, category, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO exercises (slug, name, description, category, measurement_type, movement_pattern, laterality, owner_user_id, promotion_status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id`
 
-	err := r.db.QueryRowContext(ctx, query,
-		exercise.Name, exercise.Description, exercise.Category, exercise.CreatedAt, exercise.UpdatedAt,
+	err := tx.QueryRowContext(ctx, query,
+		exercise.Slug, exercise.Name, exercise.Description, exercise.Category, exercise.MeasurementType,
+		exercise.MovementPattern, exercise.Laterality,
+		exercise.OwnerUserID, exercise.PromotionStatus,
+		exercise.CreatedAt, exercise.UpdatedAt,
 	).Scan(&exercise.ID)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return saveTaxonomy(ctx, tx, exercise)
 }
 
 func (r *ExerciseRepository) GetByID(ctx context.Context, id int) (*model.Exercise, error) {
 	query := `
-		SELECT id, name, description, category, created_at, updated_at
+		SELECT ` + exerciseColumns + `
 		FROM exercises
 		WHERE id = $1`
 
-	var exercise model.Exercise
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&exercise.ID, &exercise.Name, &exercise.Description, &exercise.Category,
-		&exercise.CreatedAt, &exercise.UpdatedAt,
-	)
+	return scanExercise(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetBySlug returns the catalog exercise seeded under the slug, including
+// a deleted one, or nil if there is none.
+func (r *ExerciseRepository) GetBySlug(ctx context.Context, slug string) (*model.Exercise, error) {
+	query := `
+		SELECT ` + exerciseColumns + `
+		FROM exercises
+		WHERE slug = $1`
+
+	exercise, err := scanExercise(r.db.QueryRowContext(ctx, query, slug))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return exercise, err
+}
+
+// GetAll returns the global catalog and the user's own custom exercises.
+func (r *ExerciseRepository) GetAll(ctx context.Context, userID int) ([]*model.Exercise, error) {
+	query := `
+		SELECT ` + exerciseColumns + `
+		FROM exercises
+		WHERE (owner_user_id IS NULL OR owner_user_id = $1) AND deleted_at IS NULL
+		ORDER BY name`
+
+	return r.query(ctx, query, userID)
+}
+
+// Search returns one page of the global catalog and the user's own custom
+// exercises that match the search, with the total number of matches.
+func (r *ExerciseRepository) Search(ctx context.Context, userID int, search model.ExerciseSearch) (*model.ExercisePage, error) {
+	where := []string{"(owner_user_id IS NULL OR owner_user_id = $1)", "deleted_at IS NULL"}
+	args := []interface{}{userID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if search.Query != "" {
+		q := arg(search.Query)
+		lower := arg(strings.ToLower(search.Query))
+		where = append(where, fmt.Sprintf(
+			"(search_vector @@ websearch_to_tsquery('english', %s) OR lower(name) %% %s OR %s <%% lower(name))",
+			q, lower, lower))
+	}
+	if search.Category != "" {
+		where = append(where, "lower(category) = lower("+arg(search.Category)+")")
+	}
+	if search.MuscleGroup != "" {
+		where = append(where, `EXISTS (
+			SELECT 1 FROM exercise_muscle_groups emg JOIN muscle_groups mg ON mg.id = emg.muscle_group_id
+			WHERE emg.exercise_id = exercises.id AND mg.slug = lower(`+arg(search.MuscleGroup)+`))`)
+	}
+	if search.Equipment != "" {
+		where = append(where, `EXISTS (
+			SELECT 1 FROM exercise_equipment ee JOIN equipment eq ON eq.id = ee.equipment_id
+			WHERE ee.exercise_id = exercises.id AND eq.slug = lower(`+arg(search.Equipment)+`))`)
+	}
+	if search.MovementPattern != "" {
+		where = append(where, "movement_pattern = lower("+arg(search.MovementPattern)+")")
+	}
+	if search.Laterality != "" {
+		where = append(where, "laterality = lower("+arg(search.Laterality)+")")
+	}
+
+	page := &model.ExercisePage{Exercises: make([]*model.Exercise, 0)}
+	countQuery := `SELECT COUNT(*) FROM exercises WHERE ` + strings.Join(where, " AND ")
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	if search.After != nil {
+		where = append(where, fmt.Sprintf("(lower(name), id) > (%s, %s)", arg(search.After.Name), arg(search.After.ID)))
+	}
+
+	// Fetch one extra row to tell whether there is another page. The cursor
+	// takes the sort key from the database so it matches lower(name) exactly.
+	query := `
+		SELECT ` + exerciseColumns + `, lower(name)
+		FROM exercises
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY lower(name), id
+		LIMIT ` + arg(search.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return &exercise, nil
+	var sortNames []string
+	for rows.Next() {
+		var sortName string
+		exercise, err := scanExercise(rows, &sortName)
+		if err != nil {
+			return nil, err
+		}
+		page.Exercises = append(page.Exercises, exercise)
+		sortNames = append(sortNames, sortName)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Exercises) > search.Limit {
+		page.Exercises = page.Exercises[:search.Limit]
+		last := page.Exercises[search.Limit-1]
+		cursor := model.ExerciseCursor{Name: sortNames[search.Limit-1], ID: last.ID}
+		page.NextCursor = cursor.String()
+	}
+
+	return page, nil
 }
 
-func (r *ExerciseRepository) GetAll(ctx context.Context) ([]*model.Exercise, error) {
+// GetPendingPromotions returns the custom exercises waiting for review.
+func (r *ExerciseRepository) GetPendingPromotions(ctx context.Context) ([]*model.Exercise, error) {
 	query := `
-		SELECT id, name, description, category, created_at, updated_at
+		SELECT ` + exerciseColumns + `
 		FROM exercises
-		ORDER BY name`
+		WHERE promotion_status = 'pending' AND deleted_at IS NULL
+		ORDER BY updated_at`
+
+	return r.query(ctx, query)
+}
 
-	rows, err := r.db.QueryContext(ctx, query)
+func (r *ExerciseRepository) query(ctx context.Context, query string, args ...interface{}) ([]*model.Exercise, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -62,16 +227,315 @@ func (r *ExerciseRepository) GetAll(ctx context.Context) ([]*model.Exercise, err
 
 	var exercises []*model.Exercise
 	for rows.Next() {
-		var exercise model.Exercise
-		err := rows.Scan(
-			&exercise.ID, &exercise.Name, &exercise.Description, &exercise.Category,
-			&exercise.CreatedAt, &exercise.UpdatedAt,
-		)
+		exercise, err := scanExercise(rows)
 		if err != nil {
 			return nil, err
 		}
-		exercises = append(exercises, &exercise)
+		exercises = append(exercises, exercise)
 	}
 
 	return exercises, nil
 }
+
+// UpdatePromotion persists a promotion request or review.
+func (r *ExerciseRepository) UpdatePromotion(ctx context.Context, exercise *model.Exercise) error {
+	query := `
+		UPDATE exercises
+		SET owner_user_id = $1, promotion_status = $2, updated_at = $3
+		WHERE id = $4`
+
+	_, err := r.db.ExecContext(ctx, query, exercise.OwnerUserID, exercise.PromotionStatus, exercise.UpdatedAt, exercise.ID)
+	return err
+}
+
+// MeasurementTypes returns how each of the given exercises is measured.
+func (r *ExerciseRepository) MeasurementTypes(ctx context.Context, ids []int) (map[int]model.MeasurementType, error) {
+	types := make(map[int]model.MeasurementType, len(ids))
+	if len(ids) == 0 {
+		return types, nil
+	}
+
+	ids64 := make([]int64, len(ids))
+	for i, id := range ids {
+		ids64[i] = int64(id)
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT id, measurement_type FROM exercises WHERE id = ANY($1)", pq.Array(ids64))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var t model.MeasurementType
+		if err := rows.Scan(&id, &t); err != nil {
+			return nil, err
+		}
+		types[id] = t
+	}
+	return types, rows.Err()
+}
+
+// NotVisible returns the IDs among ids that do not exist, are deleted or
+// belong to another user.
+func (r *ExerciseRepository) NotVisible(ctx context.Context, userID int, ids []int) ([]int, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id FROM exercises
+		WHERE id = ANY($1) AND (owner_user_id IS NULL OR owner_user_id = $2) AND deleted_at IS NULL`
+
+	ids64 := make([]int64, len(ids))
+	for i, id := range ids {
+		ids64[i] = int64(id)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids64), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	visible := make(map[int]bool, len(ids))
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		visible[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var missing []int
+	for _, id := range ids {
+		if !visible[id] {
+			missing = append(missing, id)
+			visible[id] = true
+		}
+	}
+	return missing, nil
+}
+
+// Update saves the exercise's fields and replaces its muscle groups and
+// equipment.
+func (r *ExerciseRepository) Update(ctx context.Context, exercise *model.Exercise) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE exercises
+		SET name = $1, description = $2, category = $3, measurement_type = $4,
+			movement_pattern = $5, laterality = $6, updated_at = $7
+		WHERE id = $8`
+
+	_, err = tx.ExecContext(ctx, query,
+		exercise.Name, exercise.Description, exercise.Category, exercise.MeasurementType,
+		exercise.MovementPattern, exercise.Laterality,
+		exercise.UpdatedAt, exercise.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM exercise_muscle_groups WHERE exercise_id = $1", exercise.ID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM exercise_equipment WHERE exercise_id = $1", exercise.ID); err != nil {
+		return err
+	}
+
+	if err := saveTaxonomy(ctx, tx, exercise); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// saveTaxonomy links the exercise to its muscle groups and equipment by
+// slug. The slugs are expected to be normalized already.
+func saveTaxonomy(ctx context.Context, tx *sql.Tx, exercise *model.Exercise) error {
+	muscles := []struct {
+		role  string
+		slugs []string
+	}{
+		{"primary", exercise.PrimaryMuscles},
+		{"secondary", exercise.SecondaryMuscles},
+	}
+	for _, m := range muscles {
+		if len(m.slugs) == 0 {
+			continue
+		}
+		query := `
+			INSERT INTO exercise_muscle_groups (exercise_id, muscle_group_id, role)
+			SELECT $1, id, $2 FROM muscle_groups WHERE slug = ANY($3)`
+
+		result, err := tx.ExecContext(ctx, query, exercise.ID, m.role, pq.Array(m.slugs))
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if int(n) != len(m.slugs) {
+			return model.ErrUnknownMuscleGroup
+		}
+	}
+
+	if len(exercise.Equipment) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO exercise_equipment (exercise_id, equipment_id)
+		SELECT $1, id FROM equipment WHERE slug = ANY($2)`
+
+	result, err := tx.ExecContext(ctx, query, exercise.ID, pq.Array(exercise.Equipment))
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if int(n) != len(exercise.Equipment) {
+		return model.ErrUnknownEquipment
+	}
+	return nil
+}
+
+// GetTaxonomy lists the muscle groups and equipment exercises can use.
+func (r *ExerciseRepository) GetTaxonomy(ctx context.Context) (*model.Taxonomy, error) {
+	taxonomy := &model.Taxonomy{
+		MovementPatterns: model.MovementPatterns,
+		Lateralities:     model.Lateralities,
+	}
+
+	var err error
+	if taxonomy.MuscleGroups, err = r.terms(ctx, "muscle_groups"); err != nil {
+		return nil, err
+	}
+	if taxonomy.Equipment, err = r.terms(ctx, "equipment"); err != nil {
+		return nil, err
+	}
+	return taxonomy, nil
+}
+
+func (r *ExerciseRepository) terms(ctx context.Context, table string) ([]model.TaxonomyTerm, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT slug, name FROM `+table+` ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terms := make([]model.TaxonomyTerm, 0)
+	for rows.Next() {
+		var term model.TaxonomyTerm
+		if err := rows.Scan(&term.Slug, &term.Name); err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	return terms, rows.Err()
+}
+
+// exerciseReferences are the columns that point at an exercise.
+var exerciseReferences = []struct{ table, column string }{
+	{"workout_exercises", "exercise_id"},
+	{"workout_template_exercises", "exercise_id"},
+	{"program_day_exercises", "exercise_id"},
+	{"personal_records", "exercise_id"},
+}
+
+// Delete removes the exercise, or only marks it deleted if anything still
+// refers to it. It reports whether the exercise was kept.
+func (r *ExerciseRepository) Delete(ctx context.Context, id int) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Lock the exercise so a merge or new reference cannot race the check.
+	if _, err := tx.ExecContext(ctx, "SELECT id FROM exercises WHERE id = $1 FOR UPDATE", id); err != nil {
+		return false, err
+	}
+
+	referenced := false
+	for _, ref := range exerciseReferences {
+		query := `SELECT EXISTS (SELECT 1 FROM ` + ref.table + ` WHERE ` + ref.column + ` = $1)`
+		if err := tx.QueryRowContext(ctx, query, id).Scan(&referenced); err != nil {
+			return false, err
+		}
+		if referenced {
+			break
+		}
+	}
+
+	if referenced {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE exercises SET deleted_at = $1, updated_at = $1 WHERE id = $2", time.Now(), id)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM exercises WHERE id = $1", id)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return referenced, tx.Commit()
+}
+
+// Merge points everything that refers to the source exercise at the target
+// instead and deletes the source, in one transaction. It returns the number
+// of workout entries that were moved.
+func (r *ExerciseRepository) Merge(ctx context.Context, sourceID, targetID int) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var moved int64
+	for _, ref := range exerciseReferences {
+		query := `UPDATE ` + ref.table + ` SET ` + ref.column + ` = $1 WHERE ` + ref.column + ` = $2`
+		result, err := tx.ExecContext(ctx, query, targetID, sourceID)
+		if err != nil {
+			return 0, err
+		}
+		if ref.table == "workout_exercises" {
+			if moved, err = result.RowsAffected(); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// The moved records now compete with the target's. Merging only adds
+	// competitors, so dropping the records that an earlier one of the same
+	// type beats or ties leaves exactly the history detection would give.
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM personal_records pr
+		WHERE pr.exercise_id = $1
+		  AND EXISTS (
+			SELECT 1
+			FROM personal_records earlier
+			WHERE earlier.user_id = pr.user_id
+			  AND earlier.exercise_id = pr.exercise_id
+			  AND earlier.record_type = pr.record_type
+			  AND earlier.value >= pr.value
+			  AND (earlier.achieved_at < pr.achieved_at
+			       OR (earlier.achieved_at = pr.achieved_at AND earlier.id < pr.id))
+		  )`, targetID)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM exercises WHERE id = $1", sourceID); err != nil {
+		return 0, err
+	}
+
+	return moved, tx.Commit()
+}
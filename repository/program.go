@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/yeboahd24/workout-tracker/model"
+)
+
+type ProgramRepository struct {
+	db *sql.DB
+}
+
+func NewProgramRepository(db *sql.DB) *ProgramRepository {
+	return &ProgramRepository{db: db}
+}
+
+func (r *ProgramRepository) Create(ctx context.Context, program *model.Program) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO programs (user_id, name, description, weeks, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+
+	err = tx.QueryRowContext(ctx, query,
+		program.UserID, program.Name, program.Description, program.Weeks, program.CreatedAt, program.UpdatedAt,
+	).Scan(&program.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range program.Days {
+		day := &program.Days[i]
+		day.ProgramID = program.ID
+
+		err := tx.QueryRowContext(ctx,
+			"INSERT INTO program_days (program_id, week, day, name) VALUES ($1, $2, $3, $4) RETURNING id",
+			program.ID, day.Week, day.Day, day.Name,
+		).Scan(&day.ID)
+		if err != nil {
+			return err
+		}
+
+		for j := range day.Exercises {
+			exercise := &day.Exercises[j]
+			exercise.ProgramDayID = day.ID
+			p := exercise.Progression
+
+			query := `
+				INSERT INTO program_day_exercises
+					(program_day_id, exercise_id, position, sets, reps, rule, start_weight, increment,
+					 training_max, percentages, rep_range_min, rep_range_max)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+				RETURNING id`
+
+			err := tx.QueryRowContext(ctx, query,
+				day.ID, exercise.ExerciseID, exercise.Position, exercise.Sets, exercise.Reps,
+				p.Rule, p.StartWeight, p.Increment, p.TrainingMax, pq.Array(p.Percentages),
+				p.RepRangeMin, p.RepRangeMax,
+			).Scan(&exercise.ID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetByID returns the program with its days and exercises in calendar
+// order, or nil if it does not exist.
+func (r *ProgramRepository) GetByID(ctx context.Context, id int) (*model.Program, error) {
+	query := `
+		SELECT id, user_id, name, description, weeks, created_at, updated_at
+		FROM programs
+		WHERE id = $1`
+
+	var program model.Program
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&program.ID, &program.UserID, &program.Name, &program.Description, &program.Weeks,
+		&program.CreatedAt, &program.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT d.id, d.week, d.day, d.name,
+			   e.id, e.exercise_id, e.position, e.sets, e.reps, e.rule, e.start_weight, e.increment,
+			   e.training_max, e.percentages, e.rep_range_min, e.rep_range_max
+		FROM program_days d
+		JOIN program_day_exercises e ON e.program_day_id = d.id
+		WHERE d.program_id = $1
+		ORDER BY d.week, d.day, e.position`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	program.Days = make([]model.ProgramDay, 0)
+	for rows.Next() {
+		var day model.ProgramDay
+		var e model.ProgramExercise
+		err := rows.Scan(
+			&day.ID, &day.Week, &day.Day, &day.Name,
+			&e.ID, &e.ExerciseID, &e.Position, &e.Sets, &e.Reps, &e.Progression.Rule,
+			&e.Progression.StartWeight, &e.Progression.Increment, &e.Progression.TrainingMax,
+			pq.Array(&e.Progression.Percentages), &e.Progression.RepRangeMin, &e.Progression.RepRangeMax,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(program.Days); n == 0 || program.Days[n-1].ID != day.ID {
+			day.ProgramID = program.ID
+			program.Days = append(program.Days, day)
+		}
+		e.ProgramDayID = day.ID
+		last := &program.Days[len(program.Days)-1]
+		last.Exercises = append(last.Exercises, e)
+	}
+
+	return &program, rows.Err()
+}
+
+func (r *ProgramRepository) GetByUserID(ctx context.Context, userID int) ([]*model.Program, error) {
+	query := `
+		SELECT id, user_id, name, description, weeks, created_at, updated_at
+		FROM programs
+		WHERE user_id = $1
+		ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var programs []*model.Program
+	for rows.Next() {
+		var p model.Program
+		err := rows.Scan(&p.ID, &p.UserID, &p.Name, &p.Description, &p.Weeks, &p.CreatedAt, &p.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		programs = append(programs, &p)
+	}
+
+	return programs, rows.Err()
+}
+
+func (r *ProgramRepository) Delete(ctx context.Context, id int) error {
+	query := "DELETE FROM programs WHERE id = $1"
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// CreateEnrollment records the enrollment and links it to the workouts that
+// were scheduled for it.
+func (r *ProgramRepository) CreateEnrollment(ctx context.Context, enrollment *model.ProgramEnrollment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO program_enrollments (program_id, user_id, start_date, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+
+	err = tx.QueryRowContext(ctx, query,
+		enrollment.ProgramID, enrollment.UserID, enrollment.StartDate, enrollment.CreatedAt,
+	).Scan(&enrollment.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, ew := range enrollment.Workouts {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO program_workouts (enrollment_id, workout_id, program_day_id) VALUES ($1, $2, $3)",
+			enrollment.ID, ew.WorkoutID, ew.ProgramDayID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteEnrollment removes the enrollment. Its workouts are kept.
+func (r *ProgramRepository) DeleteEnrollment(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM program_enrollments WHERE id = $1", id)
+	return err
+}
+
+// GetEnrollmentByWorkoutID returns the enrollment a workout was scheduled
+// for, or nil if the workout is not part of a program.
+func (r *ProgramRepository) GetEnrollmentByWorkoutID(ctx context.Context, workoutID int) (*model.ProgramEnrollment, error) {
+	query := `
+		SELECT e.id, e.program_id, e.user_id, e.start_date, e.created_at
+		FROM program_enrollments e
+		JOIN program_workouts pw ON pw.enrollment_id = e.id
+		WHERE pw.workout_id = $1`
+
+	var e model.ProgramEnrollment
+	err := r.db.QueryRowContext(ctx, query, workoutID).Scan(
+		&e.ID, &e.ProgramID, &e.UserID, &e.StartDate, &e.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadEnrollmentWorkouts(ctx, &e); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+func (r *ProgramRepository) GetEnrollmentsByUserID(ctx context.Context, userID int) ([]*model.ProgramEnrollment, error) {
+	query := `
+		SELECT id, program_id, user_id, start_date, created_at
+		FROM program_enrollments
+		WHERE user_id = $1
+		ORDER BY start_date DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var enrollments []*model.ProgramEnrollment
+	for rows.Next() {
+		var e model.ProgramEnrollment
+		if err := rows.Scan(&e.ID, &e.ProgramID, &e.UserID, &e.StartDate, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		enrollments = append(enrollments, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, e := range enrollments {
+		if err := r.loadEnrollmentWorkouts(ctx, e); err != nil {
+			return nil, err
+		}
+	}
+
+	return enrollments, nil
+}
+
+func (r *ProgramRepository) loadEnrollmentWorkouts(ctx context.Context, enrollment *model.ProgramEnrollment) error {
+	query := `
+		SELECT pw.workout_id, pw.program_day_id, d.week, d.day
+		FROM program_workouts pw
+		JOIN program_days d ON d.id = pw.program_day_id
+		WHERE pw.enrollment_id = $1
+		ORDER BY d.week, d.day`
+
+	rows, err := r.db.QueryContext(ctx, query, enrollment.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	enrollment.Workouts = make([]model.EnrollmentWorkout, 0)
+	for rows.Next() {
+		var ew model.EnrollmentWorkout
+		if err := rows.Scan(&ew.WorkoutID, &ew.ProgramDayID, &ew.Week, &ew.Day); err != nil {
+			return err
+		}
+		enrollment.Workouts = append(enrollment.Workouts, ew)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+)
+
+type RecordRepository struct {
+	db *sql.DB
+}
+
+func NewRecordRepository(db *sql.DB) *RecordRepository {
+	return &RecordRepository{db: db}
+}
+
+// DetectForWorkout recomputes the PR events of a workout. Records it set
+// earlier are dropped first so re-saving a workout never double counts, then
+// every entry is compared with the best record achieved before it for its
+// exercise. The new records are returned and each entry's Records field is
+// filled in.
+//
+// Records of later workouts that a new record beats or ties are dropped, as
+// they were not records when they were achieved after all. Later workouts are
+// not re-evaluated otherwise: lowering this workout's numbers does not promote
+// their sets to records until they are saved again.
+func (r *RecordRepository) DetectForWorkout(ctx context.Context, workout *model.Workout) ([]model.PersonalRecord, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	records, err := r.DetectForWorkoutTx(ctx, tx, workout)
+	if err != nil {
+		return nil, err
+	}
+
+	return records, tx.Commit()
+}
+
+// DetectForWorkoutTx detects the workout's records as part of a transaction
+// that spans other repositories, e.g. the one that creates the workout.
+func (r *RecordRepository) DetectForWorkoutTx(ctx context.Context, tx *sql.Tx, workout *model.Workout) ([]model.PersonalRecord, error) {
+	_, err := tx.ExecContext(ctx, "DELETE FROM personal_records WHERE workout_id = $1", workout.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	achievedAt := workout.ScheduledFor
+	if workout.CompletedAt != nil {
+		achievedAt = *workout.CompletedAt
+	}
+	includeSummary := workout.Status == model.StatusCompleted
+
+	type bestKey struct {
+		exerciseID int
+		recordType model.RecordType
+	}
+	best := make(map[bestKey]float64)
+
+	var records []model.PersonalRecord
+	for i := range workout.Exercises {
+		entry := &workout.Exercises[i]
+		entry.Records = nil
+
+		candidates := model.CandidateRecords(entry.PerformedSets(includeSummary))
+		for _, recordType := range model.RecordTypes {
+			candidate, ok := candidates[recordType]
+			if !ok {
+				continue
+			}
+
+			key := bestKey{entry.ExerciseID, recordType}
+			if _, loaded := best[key]; !loaded {
+				var value float64
+				err := tx.QueryRowContext(ctx, `
+					SELECT COALESCE(MAX(value), 0)
+					FROM personal_records
+					WHERE user_id = $1 AND exercise_id = $2 AND record_type = $3 AND achieved_at < $4`,
+					workout.UserID, entry.ExerciseID, recordType, achievedAt,
+				).Scan(&value)
+				if err != nil {
+					return nil, err
+				}
+				best[key] = value
+			}
+
+			if candidate.Value <= best[key] {
+				continue
+			}
+			best[key] = candidate.Value
+
+			candidate.UserID = workout.UserID
+			candidate.ExerciseID = entry.ExerciseID
+			candidate.WorkoutID = workout.ID
+			candidate.WorkoutExerciseID = entry.ID
+			candidate.AchievedAt = achievedAt
+			candidate.CreatedAt = time.Now()
+
+			query := `
+				INSERT INTO personal_records
+					(user_id, exercise_id, workout_id, workout_exercise_id, record_type, value, weight, reps, achieved_at, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+				RETURNING id`
+
+			err := tx.QueryRowContext(ctx, query,
+				candidate.UserID, candidate.ExerciseID, candidate.WorkoutID, candidate.WorkoutExerciseID,
+				candidate.Type, candidate.Value, candidate.Weight, candidate.Reps,
+				candidate.AchievedAt, candidate.CreatedAt,
+			).Scan(&candidate.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = tx.ExecContext(ctx, `
+				DELETE FROM personal_records
+				WHERE user_id = $1 AND exercise_id = $2 AND record_type = $3
+				  AND achieved_at > $4 AND value <= $5`,
+				candidate.UserID, candidate.ExerciseID, candidate.Type, candidate.AchievedAt, candidate.Value,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			entry.Records = append(entry.Records, recordType)
+			records = append(records, candidate)
+		}
+	}
+
+	return records, nil
+}
+
+// GetByUserID returns the user's PR history grouped by exercise, optionally
+// restricted to one exercise.
+func (r *RecordRepository) GetByUserID(ctx context.Context, userID, exerciseID int) ([]*model.ExerciseRecords, error) {
+	query := `
+		SELECT id, user_id, exercise_id, workout_id, workout_exercise_id, record_type,
+			   value, weight, reps, achieved_at, created_at
+		FROM personal_records
+		WHERE user_id = $1 AND ($2 = 0 OR exercise_id = $2)
+		ORDER BY exercise_id, achieved_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grouped []*model.ExerciseRecords
+	for rows.Next() {
+		var pr model.PersonalRecord
+		err := rows.Scan(
+			&pr.ID, &pr.UserID, &pr.ExerciseID, &pr.WorkoutID, &pr.WorkoutExerciseID, &pr.Type,
+			&pr.Value, &pr.Weight, &pr.Reps, &pr.AchievedAt, &pr.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(grouped); n == 0 || grouped[n-1].ExerciseID != pr.ExerciseID {
+			grouped = append(grouped, &model.ExerciseRecords{
+				ExerciseID: pr.ExerciseID,
+				Best:       make(map[model.RecordType]model.PersonalRecord),
+			})
+		}
+		group := grouped[len(grouped)-1]
+		group.History = append(group.History, pr)
+		if pr.Value > group.Best[pr.Type].Value {
+			group.Best[pr.Type] = pr
+		}
+	}
+
+	return grouped, rows.Err()
+}
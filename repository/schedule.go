@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yeboahd24/workout-tracker/model"
+)
+
+type ScheduleRepository struct {
+	db *sql.DB
+}
+
+func NewScheduleRepository(db *sql.DB) *ScheduleRepository {
+	return &ScheduleRepository{db: db}
+}
+
+const scheduleColumns = `id, user_id, template_id, name, description, timezone, starts_at,
+		       rrule, exceptions, generated_until, created_at, updated_at`
+
+// BeginTx starts a transaction for a change to a schedule and the workouts
+// generated for it, which the Tx methods of this and the workout repository
+// then run in.
+func (r *ScheduleRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return r.db.BeginTx(ctx, nil)
+}
+
+func (r *ScheduleRepository) CreateTx(ctx context.Context, tx *sql.Tx, schedule *model.Schedule) error {
+	query := `
+		INSERT INTO schedules (user_id, template_id, name, description, timezone, starts_at,
+		                       rrule, exceptions, generated_until, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`
+
+	return tx.QueryRowContext(ctx, query,
+		schedule.UserID, schedule.TemplateID, schedule.Name, schedule.Description, schedule.TimeZone,
+		schedule.StartsAt, schedule.RRule, pq.Array(schedule.Exceptions), schedule.GeneratedUntil,
+		schedule.CreatedAt, schedule.UpdatedAt,
+	).Scan(&schedule.ID)
+}
+
+// GetByID returns the schedule with the workouts generated for it, or nil if
+// it does not exist.
+func (r *ScheduleRepository) GetByID(ctx context.Context, id int) (*model.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE id = $1`
+
+	schedule, err := scanSchedule(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT workout_id, occurrence, detached
+		FROM schedule_workouts
+		WHERE schedule_id = $1
+		ORDER BY occurrence`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sw model.ScheduledWorkout
+		if err := rows.Scan(&sw.WorkoutID, &sw.Occurrence, &sw.Detached); err != nil {
+			return nil, err
+		}
+		schedule.Workouts = append(schedule.Workouts, sw)
+	}
+
+	return schedule, rows.Err()
+}
+
+func (r *ScheduleRepository) GetByUserID(ctx context.Context, userID int) ([]*model.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE user_id = $1 ORDER BY created_at DESC`
+	return r.query(ctx, query, userID)
+}
+
+// GetDue returns the schedules that have not had workouts generated up to
+// before yet.
+func (r *ScheduleRepository) GetDue(ctx context.Context, before time.Time) ([]*model.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE generated_until < $1 ORDER BY id`
+	return r.query(ctx, query, before)
+}
+
+func (r *ScheduleRepository) query(ctx context.Context, query string, args ...interface{}) ([]*model.Schedule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []*model.Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func scanSchedule(row interface{ Scan(...interface{}) error }) (*model.Schedule, error) {
+	var s model.Schedule
+	err := row.Scan(
+		&s.ID, &s.UserID, &s.TemplateID, &s.Name, &s.Description, &s.TimeZone, &s.StartsAt,
+		&s.RRule, pq.Array(&s.Exceptions), &s.GeneratedUntil, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if s.Exceptions == nil {
+		s.Exceptions = []string{}
+	}
+	return &s, nil
+}
+
+func (r *ScheduleRepository) UpdateTx(ctx context.Context, tx *sql.Tx, schedule *model.Schedule) error {
+	query := `
+		UPDATE schedules
+		SET template_id = $1, name = $2, description = $3, timezone = $4, starts_at = $5,
+		    rrule = $6, exceptions = $7, generated_until = $8, updated_at = $9
+		WHERE id = $10`
+
+	_, err := tx.ExecContext(ctx, query,
+		schedule.TemplateID, schedule.Name, schedule.Description, schedule.TimeZone, schedule.StartsAt,
+		schedule.RRule, pq.Array(schedule.Exceptions), schedule.GeneratedUntil, schedule.UpdatedAt,
+		schedule.ID,
+	)
+	return err
+}
+
+func (r *ScheduleRepository) DeleteTx(ctx context.Context, tx *sql.Tx, id int) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM schedules WHERE id = $1", id)
+	return err
+}
+
+// AddWorkoutTx records that workoutID was generated for the occurrence.
+func (r *ScheduleRepository) AddWorkoutTx(ctx context.Context, tx *sql.Tx, scheduleID, workoutID int, occurrence time.Time) error {
+	query := `
+		INSERT INTO schedule_workouts (workout_id, schedule_id, occurrence)
+		VALUES ($1, $2, $3)`
+
+	_, err := tx.ExecContext(ctx, query, workoutID, scheduleID, occurrence)
+	return err
+}
+
+// DetachTx marks a generated workout as edited on its own.
+func (r *ScheduleRepository) DetachTx(ctx context.Context, tx *sql.Tx, workoutID int) error {
+	_, err := tx.ExecContext(ctx, "UPDATE schedule_workouts SET detached = TRUE WHERE workout_id = $1", workoutID)
+	return err
+}
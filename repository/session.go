@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+)
+
+var ErrRefreshTokenReused = errors.New("refresh token already used")
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create stores a new session together with its first refresh token.
+func (r *SessionRepository) Create(ctx context.Context, session *model.Session, token *model.RefreshToken) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO sessions (id, user_id, created_at) VALUES ($1, $2, $3)",
+		session.ID, session.UserID, session.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := insertRefreshToken(ctx, tx, token); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SessionRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	query := `
+		SELECT id, session_id, user_id, token_hash, expires_at, used_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1`
+
+	var t model.RefreshToken
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&t.ID, &t.SessionID, &t.UserID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// Rotate marks old as used and stores next in the same session. If old was
+// already used, nothing is written and ErrRefreshTokenReused is returned.
+func (r *SessionRepository) Rotate(ctx context.Context, old, next *model.RefreshToken) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE refresh_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL",
+		time.Now(), old.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrRefreshTokenReused
+	}
+
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, sessionID string) error {
+	query := "UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, time.Now(), sessionID)
+	return err
+}
+
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID int) error {
+	query := "UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, time.Now(), userID)
+	return err
+}
+
+// IsActive reports whether the session exists and has not been revoked.
+func (r *SessionRepository) IsActive(ctx context.Context, sessionID string) (bool, error) {
+	var revokedAt *time.Time
+	err := r.db.QueryRowContext(ctx,
+		"SELECT revoked_at FROM sessions WHERE id = $1", sessionID,
+	).Scan(&revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return revokedAt == nil, nil
+}
+
+func insertRefreshToken(ctx context.Context, tx *sql.Tx, token *model.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (session_id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	return tx.QueryRowContext(ctx, query,
+		token.SessionID, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt,
+	).Scan(&token.ID)
+}
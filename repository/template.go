@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/yeboahd24/workout-tracker/model"
+)
+
+type TemplateRepository struct {
+	db *sql.DB
+}
+
+func NewTemplateRepository(db *sql.DB) *TemplateRepository {
+	return &TemplateRepository{db: db}
+}
+
+func (r *TemplateRepository) Create(ctx context.Context, template *model.WorkoutTemplate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO workout_templates (user_id, name, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	err = tx.QueryRowContext(ctx, query,
+		template.UserID, template.Name, template.Description, template.CreatedAt, template.UpdatedAt,
+	).Scan(&template.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertTemplateExercises(ctx, tx, template); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetByID returns the template with its exercises in order, or nil if it
+// does not exist.
+func (r *TemplateRepository) GetByID(ctx context.Context, id int) (*model.WorkoutTemplate, error) {
+	query := `
+		SELECT t.id, t.user_id, t.name, t.description, t.created_at, t.updated_at,
+			   te.id, te.exercise_id, te.position, te.target_sets, te.target_reps, te.target_weight, te.notes
+		FROM workout_templates t
+		LEFT JOIN workout_template_exercises te ON t.id = te.template_id
+		WHERE t.id = $1
+		ORDER BY te.position`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var template *model.WorkoutTemplate
+	for rows.Next() {
+		if template == nil {
+			template = &model.WorkoutTemplate{Exercises: make([]model.TemplateExercise, 0)}
+		}
+
+		var (
+			teID, exerciseID, position, sets, reps sql.NullInt64
+			weight                                 sql.NullFloat64
+			notes                                  sql.NullString
+		)
+		err := rows.Scan(
+			&template.ID, &template.UserID, &template.Name, &template.Description,
+			&template.CreatedAt, &template.UpdatedAt,
+			&teID, &exerciseID, &position, &sets, &reps, &weight, &notes,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if teID.Valid {
+			template.Exercises = append(template.Exercises, model.TemplateExercise{
+				ID:           int(teID.Int64),
+				TemplateID:   template.ID,
+				ExerciseID:   int(exerciseID.Int64),
+				Position:     int(position.Int64),
+				TargetSets:   int(sets.Int64),
+				TargetReps:   int(reps.Int64),
+				TargetWeight: weight.Float64,
+				Notes:        notes.String,
+			})
+		}
+	}
+
+	return template, rows.Err()
+}
+
+func (r *TemplateRepository) GetByUserID(ctx context.Context, userID int) ([]*model.WorkoutTemplate, error) {
+	query := `
+		SELECT id, user_id, name, description, created_at, updated_at
+		FROM workout_templates
+		WHERE user_id = $1
+		ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []*model.WorkoutTemplate
+	for rows.Next() {
+		var t model.WorkoutTemplate
+		err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.Description, &t.CreatedAt, &t.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, &t)
+	}
+
+	return templates, rows.Err()
+}
+
+func (r *TemplateRepository) Update(ctx context.Context, template *model.WorkoutTemplate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE workout_templates
+		SET name = $1, description = $2, updated_at = $3
+		WHERE id = $4`
+
+	_, err = tx.ExecContext(ctx, query,
+		template.Name, template.Description, template.UpdatedAt, template.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM workout_template_exercises WHERE template_id = $1", template.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertTemplateExercises(ctx, tx, template); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TemplateRepository) Delete(ctx context.Context, id int) error {
+	query := "DELETE FROM workout_templates WHERE id = $1"
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func insertTemplateExercises(ctx context.Context, tx *sql.Tx, template *model.WorkoutTemplate) error {
+	for i := range template.Exercises {
+		exercise := &template.Exercises[i]
+		exercise.TemplateID = template.ID
+
+		query := `
+			INSERT INTO workout_template_exercises
+				(template_id, exercise_id, position, target_sets, target_reps, target_weight, notes)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id`
+
+		err := tx.QueryRowContext(ctx, query,
+			template.ID, exercise.ExerciseID, exercise.Position,
+			exercise.TargetSets, exercise.TargetReps, exercise.TargetWeight, exercise.Notes,
+		).Scan(&exercise.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/yeboahd24/workout-tracker/model"
 )
@@ -30,13 +31,14 @@ func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
 
 func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, created_at, updated_at
+		SELECT id, username, email, password_hash, is_admin, deletion_scheduled_at, created_at, updated_at
 		FROM users
 		WHERE username = $1`
 
 	var user model.User
 	err := r.db.QueryRowContext(ctx, query, username).Scan(
-		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.IsAdmin, &user.DeletionScheduledAt,
+		&user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -47,13 +49,14 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*m
 
 func (r *UserRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, created_at, updated_at
+		SELECT id, username, email, password_hash, is_admin, deletion_scheduled_at, created_at, updated_at
 		FROM users
 		WHERE id = $1`
 
 	var user model.User
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.IsAdmin, &user.DeletionScheduledAt,
+		&user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -61,3 +64,67 @@ func (r *UserRepository) GetByID(ctx context.Context, id int) (*model.User, erro
 
 	return &user, nil
 }
+
+// SetDeletionSchedule stores when the user's account is due to be deleted,
+// or clears it.
+func (r *UserRepository) SetDeletionSchedule(ctx context.Context, user *model.User) error {
+	query := "UPDATE users SET deletion_scheduled_at = $1, updated_at = $2 WHERE id = $3"
+	_, err := r.db.ExecContext(ctx, query, user.DeletionScheduledAt, user.UpdatedAt, user.ID)
+	return err
+}
+
+// GetDueForDeletion returns the IDs of accounts whose grace period ended
+// before now.
+func (r *UserRepository) GetDueForDeletion(ctx context.Context, now time.Time) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id FROM users WHERE deletion_scheduled_at <= $1 ORDER BY id", now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Purge deletes the account and everything that belongs to it in one
+// transaction, provided its deletion is still due. Workouts, their entries
+// and sets, sessions, templates, programs, schedules and records go with
+// it through ON DELETE CASCADE. It reports whether the account was deleted.
+func (r *UserRepository) Purge(ctx context.Context, userID int, now time.Time) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Lock the row so a cancellation cannot slip in between the check and
+	// the delete.
+	var scheduledAt *time.Time
+	err = tx.QueryRowContext(ctx,
+		"SELECT deletion_scheduled_at FROM users WHERE id = $1 FOR UPDATE", userID,
+	).Scan(&scheduledAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if scheduledAt == nil || scheduledAt.After(now) {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", userID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
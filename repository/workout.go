@@ -3,9 +3,9 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/yeboahd24/workout-tracker/model"
 )
 
@@ -17,6 +17,12 @@ func NewWorkoutRepository(db *sql.DB) *WorkoutRepository {
 	return &WorkoutRepository{db: db}
 }
 
+// BeginTx starts a transaction for a change that spans workouts and other
+// repositories, which their Tx methods then run in.
+func (r *WorkoutRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return r.db.BeginTx(ctx, nil)
+}
+
 func (r *WorkoutRepository) Create(ctx context.Context, workout *model.Workout) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -24,44 +30,52 @@ func (r *WorkoutRepository) Create(ctx context.Context, workout *model.Workout)
 	}
 	defer tx.Rollback()
 
+	if err := r.CreateTx(ctx, tx, workout); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateTx creates the workout as part of a transaction that spans other
+// repositories.
+func (r *WorkoutRepository) CreateTx(ctx context.Context, tx *sql.Tx, workout *model.Workout) error {
 	// Insert workout
 	query := `
-		INSERT INTO workouts (user_id, name, description, scheduled_for, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO workouts (user_id, name, description, scheduled_for, status, started_at, completed_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id`
 
-	err = tx.QueryRowContext(ctx, query,
+	err := tx.QueryRowContext(ctx, query,
 		workout.UserID, workout.Name, workout.Description, workout.ScheduledFor,
+		workout.Status, workout.StartedAt, workout.CompletedAt,
 		workout.CreatedAt, workout.UpdatedAt,
 	).Scan(&workout.ID)
 	if err != nil {
 		return err
 	}
 
-	// Insert workout exercises
-	for _, exercise := range workout.Exercises {
-		query := `
-			INSERT INTO workout_exercises (workout_id, exercise_id, sets, reps, weight, notes)
-			VALUES ($1, $2, $3, $4, $5, $6)`
-
-		_, err = tx.ExecContext(ctx, query,
-			workout.ID, exercise.ExerciseID, exercise.Sets, exercise.Reps, exercise.Weight, exercise.Notes,
-		)
-		if err != nil {
-			return err
-		}
+	// Everything in a new workout is new, even when it was copied from
+	// another one
+	for i := range workout.Exercises {
+		workout.Exercises[i].ID = 0
 	}
 
-	return tx.Commit()
+	// Insert workout exercises
+	return saveWorkoutExercises(ctx, tx, workout)
 }
 
 func (r *WorkoutRepository) GetByID(ctx context.Context, id int) (*model.Workout, error) {
 	query := `
-		SELECT w.id, w.user_id, w.name, w.description, w.scheduled_for, w.created_at, w.updated_at,
-			   we.id, we.exercise_id, we.sets, we.reps, we.weight, we.notes
+		SELECT w.id, w.user_id, w.name, w.description, w.scheduled_for,
+			   w.status, w.started_at, w.completed_at, w.created_at, w.updated_at,
+			   we.id, we.exercise_id, we.position, COALESCE(g.label, ''),
+			   we.sets, we.reps, we.weight, we.distance, we.duration, we.notes
 		FROM workouts w
 		LEFT JOIN workout_exercises we ON w.id = we.workout_id
-		WHERE w.id = $1`
+		LEFT JOIN workout_exercise_groups g ON g.id = we.group_id
+		WHERE w.id = $1
+		ORDER BY we.position, we.id`
 
 	rows, err := r.db.QueryContext(ctx, query, id)
 	if err != nil {
@@ -75,32 +89,277 @@ func (r *WorkoutRepository) GetByID(ctx context.Context, id int) (*model.Workout
 			workout = &model.Workout{}
 		}
 
-		var we model.WorkoutExercise
+		var (
+			weID, exerciseID, position, sets, reps, duration sql.NullInt64
+			weight, distance                                 sql.NullFloat64
+			notes                                            sql.NullString
+			group                                            string
+		)
 		err := rows.Scan(
 			&workout.ID, &workout.UserID, &workout.Name, &workout.Description, &workout.ScheduledFor,
-			&workout.CreatedAt, &workout.UpdatedAt,
-			&we.ID, &we.ExerciseID, &we.Sets, &we.Reps, &we.Weight, &we.Notes,
+			&workout.Status, &workout.StartedAt, &workout.CompletedAt, &workout.CreatedAt, &workout.UpdatedAt,
+			&weID, &exerciseID, &position, &group,
+			&sets, &reps, &weight, &distance, &duration, &notes,
 		)
 		if err != nil {
 			return nil, err
 		}
 
-		if we.ID != 0 {
-			workout.Exercises = append(workout.Exercises, we)
+		if weID.Valid {
+			workout.Exercises = append(workout.Exercises, model.WorkoutExercise{
+				ID:         int(weID.Int64),
+				WorkoutID:  workout.ID,
+				ExerciseID: int(exerciseID.Int64),
+				Position:   int(position.Int64),
+				Group:      group,
+				Sets:       int(sets.Int64),
+				Reps:       int(reps.Int64),
+				Weight:     weight.Float64,
+				Distance:   distance.Float64,
+				Duration:   int(duration.Int64),
+				Notes:      notes.String,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if workout != nil {
+		if err := r.loadGroups(ctx, workout); err != nil {
+			return nil, err
+		}
+		if err := r.loadSets(ctx, workout); err != nil {
+			return nil, err
 		}
 	}
 
 	return workout, nil
 }
 
-func (r *WorkoutRepository) GetByUserID(ctx context.Context, userID int) ([]*model.Workout, error) {
+// loadGroups attaches the workout's exercise groups.
+func (r *WorkoutRepository) loadGroups(ctx context.Context, workout *model.Workout) error {
+	query := `
+		SELECT id, workout_id, label, type, rounds, rest_seconds
+		FROM workout_exercise_groups
+		WHERE workout_id = $1
+		ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, workout.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	workout.Groups = make([]model.ExerciseGroup, 0)
+	for rows.Next() {
+		var g model.ExerciseGroup
+		if err := rows.Scan(&g.ID, &g.WorkoutID, &g.Label, &g.Type, &g.Rounds, &g.RestSeconds); err != nil {
+			return err
+		}
+		workout.Groups = append(workout.Groups, g)
+	}
+
+	return rows.Err()
+}
+
+// loadSets attaches the logged sets to each of the workout's exercises.
+func (r *WorkoutRepository) loadSets(ctx context.Context, workout *model.Workout) error {
+	query := `
+		SELECT ws.id, ws.workout_exercise_id, ws.set_number, ws.reps, ws.weight, ws.distance, ws.duration,
+			   ws.rpe, ws.rir, ws.tempo, ws.set_type, ws.completed
+		FROM workout_sets ws
+		JOIN workout_exercises we ON we.id = ws.workout_exercise_id
+		WHERE we.workout_id = $1
+		ORDER BY ws.workout_exercise_id, ws.set_number`
+
+	rows, err := r.db.QueryContext(ctx, query, workout.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	index := make(map[int]int, len(workout.Exercises))
+	for i, we := range workout.Exercises {
+		index[we.ID] = i
+	}
+
+	for rows.Next() {
+		var s model.WorkoutSet
+		err := rows.Scan(
+			&s.ID, &s.WorkoutExerciseID, &s.SetNumber, &s.Reps, &s.Weight, &s.Distance, &s.Duration,
+			&s.RPE, &s.RIR, &s.Tempo, &s.SetType, &s.Completed,
+		)
+		if err != nil {
+			return err
+		}
+
+		if i, ok := index[s.WorkoutExerciseID]; ok {
+			workout.Exercises[i].SetLog = append(workout.Exercises[i].SetLog, s)
+		}
+	}
+
+	return rows.Err()
+}
+
+// saveWorkoutExercises writes the workout's groups, its exercises in order
+// and their logged sets, filling in the generated IDs and positions. Groups
+// are matched by label, exercises by ID and sets by number: those that are
+// kept are updated in place and keep their IDs, the others are deleted or
+// inserted.
+func saveWorkoutExercises(ctx context.Context, tx *sql.Tx, workout *model.Workout) error {
+	labels := make([]string, len(workout.Groups))
+	for i, g := range workout.Groups {
+		labels[i] = g.Label
+	}
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM workout_exercise_groups
+		WHERE workout_id = $1 AND NOT (label = ANY($2))`, workout.ID, pq.Array(labels))
+	if err != nil {
+		return err
+	}
+
+	groupIDs := make(map[string]int, len(workout.Groups))
+	for i := range workout.Groups {
+		group := &workout.Groups[i]
+		group.WorkoutID = workout.ID
+
+		query := `
+			INSERT INTO workout_exercise_groups (workout_id, label, type, rounds, rest_seconds)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (workout_id, label) DO UPDATE
+			SET type = EXCLUDED.type, rounds = EXCLUDED.rounds, rest_seconds = EXCLUDED.rest_seconds
+			RETURNING id`
+
+		err := tx.QueryRowContext(ctx, query,
+			workout.ID, group.Label, group.Type, group.Rounds, group.RestSeconds,
+		).Scan(&group.ID)
+		if err != nil {
+			return err
+		}
+		groupIDs[group.Label] = group.ID
+	}
+
+	// Dropped exercises take their logged sets with them
+	kept := make([]int64, 0, len(workout.Exercises))
+	for _, e := range workout.Exercises {
+		if e.ID != 0 {
+			kept = append(kept, int64(e.ID))
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM workout_exercises
+		WHERE workout_id = $1 AND NOT (id = ANY($2))`, workout.ID, pq.Array(kept))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range workout.Exercises {
+		exercise := &workout.Exercises[i]
+		exercise.WorkoutID = workout.ID
+		exercise.Position = i + 1
+
+		var groupID *int
+		if id, ok := groupIDs[exercise.Group]; ok {
+			groupID = &id
+		}
+
+		if exercise.ID == 0 {
+			query := `
+				INSERT INTO workout_exercises (workout_id, exercise_id, position, group_id, sets, reps, weight, distance, duration, notes)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+				RETURNING id`
+
+			err := tx.QueryRowContext(ctx, query,
+				workout.ID, exercise.ExerciseID, exercise.Position, groupID,
+				exercise.Sets, exercise.Reps, exercise.Weight,
+				exercise.Distance, exercise.Duration, exercise.Notes,
+			).Scan(&exercise.ID)
+			if err != nil {
+				return err
+			}
+		} else {
+			// Positions may clash until every exercise is written; the
+			// constraint is only checked on commit.
+			query := `
+				UPDATE workout_exercises
+				SET exercise_id = $1, position = $2, group_id = $3, sets = $4, reps = $5, weight = $6,
+					distance = $7, duration = $8, notes = $9, updated_at = $10
+				WHERE id = $11 AND workout_id = $12`
+
+			result, err := tx.ExecContext(ctx, query,
+				exercise.ExerciseID, exercise.Position, groupID,
+				exercise.Sets, exercise.Reps, exercise.Weight,
+				exercise.Distance, exercise.Duration, exercise.Notes, now,
+				exercise.ID, workout.ID,
+			)
+			if err != nil {
+				return err
+			}
+			if n, err := result.RowsAffected(); err != nil {
+				return err
+			} else if n == 0 {
+				return model.ErrUnknownEntry
+			}
+		}
+
+		if err := saveWorkoutSets(ctx, tx, exercise, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveWorkoutSets writes the logged sets of a workout exercise, keeping the
+// IDs of the sets whose numbers were already logged.
+func saveWorkoutSets(ctx context.Context, tx *sql.Tx, exercise *model.WorkoutExercise, now time.Time) error {
+	numbers := make([]int64, len(exercise.SetLog))
+	for i, s := range exercise.SetLog {
+		numbers[i] = int64(s.SetNumber)
+	}
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM workout_sets
+		WHERE workout_exercise_id = $1 AND NOT (set_number = ANY($2))`, exercise.ID, pq.Array(numbers))
+	if err != nil {
+		return err
+	}
+
+	for j := range exercise.SetLog {
+		set := &exercise.SetLog[j]
+		set.WorkoutExerciseID = exercise.ID
+
+		query := `
+			INSERT INTO workout_sets (workout_exercise_id, set_number, reps, weight, distance, duration, rpe, rir, tempo, set_type, completed)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (workout_exercise_id, set_number) DO UPDATE
+			SET reps = EXCLUDED.reps, weight = EXCLUDED.weight, distance = EXCLUDED.distance,
+				duration = EXCLUDED.duration, rpe = EXCLUDED.rpe, rir = EXCLUDED.rir, tempo = EXCLUDED.tempo,
+				set_type = EXCLUDED.set_type, completed = EXCLUDED.completed, updated_at = $12
+			RETURNING id`
+
+		err := tx.QueryRowContext(ctx, query,
+			set.WorkoutExerciseID, set.SetNumber, set.Reps, set.Weight, set.Distance, set.Duration,
+			set.RPE, set.RIR, set.Tempo, set.SetType, set.Completed, now,
+		).Scan(&set.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetByUserID lists the user's workouts, optionally restricted to one status.
+func (r *WorkoutRepository) GetByUserID(ctx context.Context, userID int, status model.WorkoutStatus) ([]*model.Workout, error) {
 	query := `
-		SELECT id, user_id, name, description, scheduled_for, created_at, updated_at
+		SELECT id, user_id, name, description, scheduled_for, status, started_at, completed_at, created_at, updated_at
 		FROM workouts
-		WHERE user_id = $1
+		WHERE user_id = $1 AND ($2::varchar = '' OR status = $2::varchar)
 		ORDER BY scheduled_for DESC`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := r.db.QueryContext(ctx, query, userID, status)
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +370,7 @@ func (r *WorkoutRepository) GetByUserID(ctx context.Context, userID int) ([]*mod
 		var w model.Workout
 		err := rows.Scan(
 			&w.ID, &w.UserID, &w.Name, &w.Description, &w.ScheduledFor,
-			&w.CreatedAt, &w.UpdatedAt,
+			&w.Status, &w.StartedAt, &w.CompletedAt, &w.CreatedAt, &w.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -129,40 +388,159 @@ func (r *WorkoutRepository) Update(ctx context.Context, workout *model.Workout)
 	}
 	defer tx.Rollback()
 
+	if err := r.UpdateTx(ctx, tx, workout); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateTx updates the workout as part of a transaction that spans other
+// repositories.
+func (r *WorkoutRepository) UpdateTx(ctx context.Context, tx *sql.Tx, workout *model.Workout) error {
 	// Update workout
 	query := `
 		UPDATE workouts
 		SET name = $1, description = $2, scheduled_for = $3, updated_at = $4
 		WHERE id = $5`
 
-	_, err = tx.ExecContext(ctx, query,
+	_, err := tx.ExecContext(ctx, query,
 		workout.Name, workout.Description, workout.ScheduledFor, workout.UpdatedAt, workout.ID,
 	)
 	if err != nil {
 		return err
 	}
 
-	// Delete existing workout exercises
-	_, err = tx.ExecContext(ctx, "DELETE FROM workout_exercises WHERE workout_id = $1", workout.ID)
+	// Save groups and workout exercises, keeping the IDs of those that stay
+	return saveWorkoutExercises(ctx, tx, workout)
+}
+
+// UpdateStatus persists a status transition made on the model.
+func (r *WorkoutRepository) UpdateStatus(ctx context.Context, workout *model.Workout) error {
+	query := `
+		UPDATE workouts
+		SET status = $1, started_at = $2, completed_at = $3, updated_at = $4
+		WHERE id = $5`
+
+	_, err := r.db.ExecContext(ctx, query,
+		workout.Status, workout.StartedAt, workout.CompletedAt, workout.UpdatedAt, workout.ID,
+	)
+	return err
+}
+
+// LogSet stores a single set logged during a live session, replacing the set
+// with the same number if there is one, and marks its workout as changed.
+func (r *WorkoutRepository) LogSet(ctx context.Context, set *model.WorkoutSet) error {
+	query := `
+		WITH logged AS (
+			INSERT INTO workout_sets (workout_exercise_id, set_number, reps, weight, distance, duration, rpe, rir, tempo, set_type, completed)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (workout_exercise_id, set_number) DO UPDATE
+			SET reps = EXCLUDED.reps, weight = EXCLUDED.weight, distance = EXCLUDED.distance,
+				duration = EXCLUDED.duration, rpe = EXCLUDED.rpe, rir = EXCLUDED.rir, tempo = EXCLUDED.tempo,
+				set_type = EXCLUDED.set_type, completed = EXCLUDED.completed, updated_at = $12
+			RETURNING id
+		), workout AS (
+			UPDATE workouts SET updated_at = $12
+			WHERE id = (SELECT workout_id FROM workout_exercises WHERE id = $1)
+		)
+		SELECT id FROM logged`
+
+	return r.db.QueryRowContext(ctx, query,
+		set.WorkoutExerciseID, set.SetNumber, set.Reps, set.Weight, set.Distance, set.Duration,
+		set.RPE, set.RIR, set.Tempo, set.SetType, set.Completed, time.Now(),
+	).Scan(&set.ID)
+}
+
+// UpdateExerciseTarget rewrites the prescription of a single workout exercise
+// and marks its workout as changed.
+func (r *WorkoutRepository) UpdateExerciseTarget(ctx context.Context, workoutExerciseID, sets, reps int, weight float64) error {
+	query := `
+		WITH entry AS (
+			UPDATE workout_exercises
+			SET sets = $1, reps = $2, weight = $3, updated_at = $4
+			WHERE id = $5
+			RETURNING workout_id
+		)
+		UPDATE workouts SET updated_at = $4
+		WHERE id IN (SELECT workout_id FROM entry)`
+
+	_, err := r.db.ExecContext(ctx, query, sets, reps, weight, time.Now(), workoutExerciseID)
+	return err
+}
+
+// GetCalendar returns the user's workouts scheduled at or after since, oldest
+// first, with their exercises but not their logged sets.
+func (r *WorkoutRepository) GetCalendar(ctx context.Context, userID int, since time.Time) ([]*model.Workout, error) {
+	query := `
+		SELECT w.id, w.user_id, w.name, w.description, w.scheduled_for,
+			   w.status, w.started_at, w.completed_at, w.created_at, w.updated_at,
+			   we.id, we.exercise_id, we.position, we.sets, we.reps, we.weight, we.distance, we.duration, we.notes
+		FROM workouts w
+		LEFT JOIN workout_exercises we ON w.id = we.workout_id
+		WHERE w.user_id = $1 AND w.scheduled_for >= $2
+		ORDER BY w.scheduled_for, w.id, we.position, we.id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	// Insert updated workout exercises
-	for _, exercise := range workout.Exercises {
-		query := `
-			INSERT INTO workout_exercises (workout_id, exercise_id, sets, reps, weight, notes)
-			VALUES ($1, $2, $3, $4, $5, $6)`
+	var workouts []*model.Workout
+	var workout *model.Workout
+	for rows.Next() {
+		var w model.Workout
+		var (
+			weID, exerciseID, position, sets, reps, duration sql.NullInt64
+			weight, distance                                 sql.NullFloat64
+			notes                                            sql.NullString
+		)
 
-		_, err = tx.ExecContext(ctx, query,
-			workout.ID, exercise.ExerciseID, exercise.Sets, exercise.Reps, exercise.Weight, exercise.Notes,
+		err := rows.Scan(
+			&w.ID, &w.UserID, &w.Name, &w.Description, &w.ScheduledFor,
+			&w.Status, &w.StartedAt, &w.CompletedAt, &w.CreatedAt, &w.UpdatedAt,
+			&weID, &exerciseID, &position, &sets, &reps, &weight, &distance, &duration, &notes,
 		)
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		if workout == nil || workout.ID != w.ID {
+			workout = &w
+			workouts = append(workouts, workout)
+		}
+		if weID.Valid {
+			workout.Exercises = append(workout.Exercises, model.WorkoutExercise{
+				ID:         int(weID.Int64),
+				WorkoutID:  workout.ID,
+				ExerciseID: int(exerciseID.Int64),
+				Position:   int(position.Int64),
+				Sets:       int(sets.Int64),
+				Reps:       int(reps.Int64),
+				Weight:     weight.Float64,
+				Distance:   distance.Float64,
+				Duration:   int(duration.Int64),
+				Notes:      notes.String,
+			})
 		}
 	}
 
-	return tx.Commit()
+	return workouts, rows.Err()
+}
+
+// Exists reports whether the user already has a workout with this name
+// scheduled for exactly this time.
+func (r *WorkoutRepository) Exists(ctx context.Context, userID int, name string, scheduledFor time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM workouts
+			WHERE user_id = $1 AND name = $2 AND scheduled_for = $3
+		)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, userID, name, scheduledFor).Scan(&exists)
+	return exists, err
 }
 
 func (r *WorkoutRepository) Delete(ctx context.Context, id int) error {
@@ -171,72 +549,176 @@ func (r *WorkoutRepository) Delete(ctx context.Context, id int) error {
 	return err
 }
 
-func (r *WorkoutRepository) GenerateReport(ctx context.Context, userID int, startDate, endDate string) (map[string]interface{}, error) {
-	// Parse dates
-	start, err := time.Parse("2006-01-02", startDate)
+// DeleteTx deletes the workout as part of a transaction that spans other
+// repositories.
+func (r *WorkoutRepository) DeleteTx(ctx context.Context, tx *sql.Tx, id int) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM workouts WHERE id = $1", id)
+	return err
+}
+
+// GetLoggedSets flattens the sets performed in the user's completed workouts
+// between start and end. Individually logged sets are used when an entry has
+// them (completed, non-warm-up only); otherwise the entry's summary stands
+// in for its sets. exerciseID 0 means every exercise.
+func (r *WorkoutRepository) GetLoggedSets(ctx context.Context, userID, exerciseID int, start, end time.Time) ([]model.LoggedSet, error) {
+	query := `
+		SELECT we.exercise_id, w.scheduled_for, ws.weight, ws.reps
+		FROM workouts w
+		JOIN workout_exercises we ON we.workout_id = w.id
+		JOIN workout_sets ws ON ws.workout_exercise_id = we.id
+		WHERE w.user_id = $1 AND w.status = 'completed' AND w.scheduled_for BETWEEN $2 AND $3
+		  AND ($4 = 0 OR we.exercise_id = $4)
+		  AND ws.completed AND ws.set_type <> 'warmup'
+		UNION ALL
+		SELECT we.exercise_id, w.scheduled_for, COALESCE(we.weight, 0), we.reps
+		FROM workouts w
+		JOIN workout_exercises we ON we.workout_id = w.id
+		WHERE w.user_id = $1 AND w.status = 'completed' AND w.scheduled_for BETWEEN $2 AND $3
+		  AND ($4 = 0 OR we.exercise_id = $4)
+		  AND NOT EXISTS (SELECT 1 FROM workout_sets ws WHERE ws.workout_exercise_id = we.id)`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, start, end, exerciseID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid start date: %v", err)
+		return nil, err
 	}
-	end, err := time.Parse("2006-01-02", endDate)
-	if err != nil {
-		return nil, fmt.Errorf("invalid end date: %v", err)
+	defer rows.Close()
+
+	var sets []model.LoggedSet
+	for rows.Next() {
+		var s model.LoggedSet
+		if err := rows.Scan(&s.ExerciseID, &s.PerformedAt, &s.Weight, &s.Reps); err != nil {
+			return nil, err
+		}
+		sets = append(sets, s)
 	}
 
-	// Fetch workouts within the date range
+	return sets, rows.Err()
+}
+
+// GenerateReport collects the user's workouts in the query's date range,
+// including those without any exercises, and aggregates them.
+func (r *WorkoutRepository) GenerateReport(ctx context.Context, userID int, q model.ReportQuery) (*model.Report, error) {
 	query := `
-        SELECT w.id, w.name, w.scheduled_for, we.exercise_id, we.sets, we.reps, we.weight
-        FROM workouts w
-        JOIN workout_exercises we ON w.id = we.workout_id
-        WHERE w.user_id = $1 AND w.scheduled_for BETWEEN $2 AND $3
-        ORDER BY w.scheduled_for
-    `
-	rows, err := r.db.QueryContext(ctx, query, userID, start, end)
+		SELECT w.id, w.name, w.scheduled_for, w.status, w.started_at, w.completed_at,
+			   we.id, we.exercise_id, we.sets, we.reps, we.weight, we.distance, we.duration, we.notes,
+			   COALESCE(e.name, ''), COALESCE(e.category, ''),
+			   ARRAY(SELECT mg.slug FROM exercise_muscle_groups emg JOIN muscle_groups mg ON mg.id = emg.muscle_group_id
+					 WHERE emg.exercise_id = e.id AND emg.role = 'primary' ORDER BY mg.slug),
+			   ARRAY(SELECT mg.slug FROM exercise_muscle_groups emg JOIN muscle_groups mg ON mg.id = emg.muscle_group_id
+					 WHERE emg.exercise_id = e.id AND emg.role = 'secondary' ORDER BY mg.slug)
+		FROM workouts w
+		LEFT JOIN workout_exercises we ON w.id = we.workout_id
+		LEFT JOIN exercises e ON e.id = we.exercise_id
+		WHERE w.user_id = $1 AND w.scheduled_for BETWEEN $2 AND $3
+		  AND ($4::varchar = '' OR w.status = $4::varchar)
+		ORDER BY w.scheduled_for, w.id, we.position, we.id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, q.Start, q.End, q.Status)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	// Process the results
-	workouts := make(map[int]map[string]interface{})
-	var totalWorkouts, totalExercises int
+	var workouts []model.ReportWorkout
+	entries := make(map[int]*model.ReportEntry)
 	for rows.Next() {
-		var workoutID int
-		var workoutName string
-		var scheduledFor time.Time
-		var exerciseID, sets, reps int
-		var weight float64
+		var w model.ReportWorkout
+		var (
+			weID, exerciseID, sets, reps, duration sql.NullInt64
+			weight, distance                       sql.NullFloat64
+			notes                                  sql.NullString
+			exerciseName, category                 string
+			primary, secondary                     []string
+		)
 
-		err := rows.Scan(&workoutID, &workoutName, &scheduledFor, &exerciseID, &sets, &reps, &weight)
+		err := rows.Scan(
+			&w.ID, &w.Name, &w.ScheduledFor, &w.Status, &w.StartedAt, &w.CompletedAt,
+			&weID, &exerciseID, &sets, &reps, &weight, &distance, &duration, &notes,
+			&exerciseName, &category, pq.Array(&primary), pq.Array(&secondary),
+		)
 		if err != nil {
 			return nil, err
 		}
 
-		if _, exists := workouts[workoutID]; !exists {
-			workouts[workoutID] = map[string]interface{}{
-				"name":          workoutName,
-				"scheduled_for": scheduledFor,
-				"exercises":     []map[string]interface{}{},
-			}
-			totalWorkouts++
+		if n := len(workouts); n == 0 || workouts[n-1].ID != w.ID {
+			w.Exercises = make([]model.ReportEntry, 0)
+			workouts = append(workouts, w)
+		}
+
+		if weID.Valid {
+			current := &workouts[len(workouts)-1]
+			current.Exercises = append(current.Exercises, model.ReportEntry{
+				WorkoutExercise: model.WorkoutExercise{
+					ID:         int(weID.Int64),
+					WorkoutID:  w.ID,
+					ExerciseID: int(exerciseID.Int64),
+					Sets:       int(sets.Int64),
+					Reps:       int(reps.Int64),
+					Weight:     weight.Float64,
+					Distance:   distance.Float64,
+					Duration:   int(duration.Int64),
+					Notes:      notes.String,
+				},
+				ExerciseName:     exerciseName,
+				Category:         category,
+				PrimaryMuscles:   primary,
+				SecondaryMuscles: secondary,
+			})
 		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Pointers are taken once the slices have stopped growing.
+	for i := range workouts {
+		for j := range workouts[i].Exercises {
+			entries[workouts[i].Exercises[j].ID] = &workouts[i].Exercises[j]
+		}
+	}
+
+	if len(entries) > 0 {
+		if err := r.loadReportSets(ctx, userID, q, entries); err != nil {
+			return nil, err
+		}
+	}
+
+	return model.BuildReport(q, workouts), nil
+}
+
+// loadReportSets attaches the logged sets of the report's workouts to their
+// entries.
+func (r *WorkoutRepository) loadReportSets(ctx context.Context, userID int, q model.ReportQuery, entries map[int]*model.ReportEntry) error {
+	query := `
+		SELECT ws.id, ws.workout_exercise_id, ws.set_number, ws.reps, ws.weight, ws.distance, ws.duration,
+			   ws.rpe, ws.rir, ws.tempo, ws.set_type, ws.completed
+		FROM workouts w
+		JOIN workout_exercises we ON w.id = we.workout_id
+		JOIN workout_sets ws ON ws.workout_exercise_id = we.id
+		WHERE w.user_id = $1 AND w.scheduled_for BETWEEN $2 AND $3
+		  AND ($4::varchar = '' OR w.status = $4::varchar)
+		ORDER BY ws.workout_exercise_id, ws.set_number`
 
-		workouts[workoutID]["exercises"] = append(workouts[workoutID]["exercises"].([]map[string]interface{}), map[string]interface{}{
-			"exercise_id": exerciseID,
-			"sets":        sets,
-			"reps":        reps,
-			"weight":      weight,
-		})
-		totalExercises++
+	rows, err := r.db.QueryContext(ctx, query, userID, q.Start, q.End, q.Status)
+	if err != nil {
+		return err
 	}
+	defer rows.Close()
 
-	// Prepare the final report
-	report := map[string]interface{}{
-		"start_date":      startDate,
-		"end_date":        endDate,
-		"total_workouts":  totalWorkouts,
-		"total_exercises": totalExercises,
-		"workouts":        workouts,
+	for rows.Next() {
+		var s model.WorkoutSet
+		err := rows.Scan(
+			&s.ID, &s.WorkoutExerciseID, &s.SetNumber, &s.Reps, &s.Weight, &s.Distance, &s.Duration,
+			&s.RPE, &s.RIR, &s.Tempo, &s.SetType, &s.Completed,
+		)
+		if err != nil {
+			return err
+		}
+
+		if entry, ok := entries[s.WorkoutExerciseID]; ok {
+			entry.SetLog = append(entry.SetLog, s)
+		}
 	}
 
-	return report, nil
+	return rows.Err()
 }
@@ -6,7 +6,16 @@ import (
 	"github.com/yeboahd24/workout-tracker/handler"
 	"github.com/yeboahd24/workout-tracker/middleware"
 	"github.com/yeboahd24/workout-tracker/repository"
+	"github.com/yeboahd24/workout-tracker/service"
 	"net/http"
+	"time"
+)
+
+// The action-style exercise and workout routes such as /workouts/create were
+// replaced by method-scoped ones and answer until the sunset date.
+var (
+	legacyRoutesDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	legacyRoutesSunsetAt     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
 )
 
 func SetupRouter(db *sql.DB, cfg *config.Config) http.Handler {
@@ -14,40 +23,219 @@ func SetupRouter(db *sql.DB, cfg *config.Config) http.Handler {
 
 	// Create repositories
 	userRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
 	exerciseRepo := repository.NewExerciseRepository(db)
 	workoutRepo := repository.NewWorkoutRepository(db)
+	templateRepo := repository.NewTemplateRepository(db)
+	programRepo := repository.NewProgramRepository(db)
+	recordRepo := repository.NewRecordRepository(db)
+	calendarRepo := repository.NewCalendarRepository(db)
+	scheduleRepo := repository.NewScheduleRepository(db)
+
+	// Create services
+	programService := service.NewProgramService(programRepo, workoutRepo)
+	importService := service.NewImportService(exerciseRepo, workoutRepo, recordRepo)
+	accountService := service.NewAccountService(userRepo, sessionRepo, exerciseRepo, workoutRepo, recordRepo)
+	scheduleService := service.NewScheduleService(scheduleRepo, workoutRepo, templateRepo)
+	liveService := service.NewLiveService(workoutRepo)
 
 	// Create handlers
-	authHandler := handler.NewAuthHandler(userRepo, cfg.JWTSecret)
-	exerciseHandler := handler.NewExerciseHandler(exerciseRepo)
-	workoutHandler := handler.NewWorkoutHandler(workoutRepo)
+	authHandler := handler.NewAuthHandler(userRepo, sessionRepo, cfg.JWTSecret)
+	exerciseHandler := handler.NewExerciseHandler(exerciseRepo, userRepo)
+	workoutHandler := handler.NewWorkoutHandler(workoutRepo, exerciseRepo, recordRepo, programService, liveService)
+	liveHandler := handler.NewLiveHandler(workoutRepo, exerciseRepo, recordRepo, liveService)
+	templateHandler := handler.NewTemplateHandler(templateRepo, workoutRepo, exerciseRepo)
+	programHandler := handler.NewProgramHandler(programRepo, exerciseRepo, programService)
+	recordHandler := handler.NewRecordHandler(recordRepo)
+	analyticsHandler := handler.NewAnalyticsHandler(workoutRepo)
+	importHandler := handler.NewImportHandler(importService)
+	accountHandler := handler.NewAccountHandler(accountService)
+	calendarHandler := handler.NewCalendarHandler(calendarRepo, workoutRepo, exerciseRepo)
+	scheduleHandler := handler.NewScheduleHandler(scheduleRepo, templateRepo, scheduleService)
+
+	auth := middleware.AuthMiddleware(cfg.JWTSecret, sessionRepo)
+	admin := middleware.AdminMiddleware(userRepo)
+	deprecated := func(successor string) func(http.Handler) http.Handler {
+		return middleware.DeprecationMiddleware(successor, legacyRoutesDeprecatedAt, legacyRoutesSunsetAt)
+	}
 
 	// Auth routes
 	mux.HandleFunc("/signup", authHandler.SignUp)
 	mux.HandleFunc("/login", authHandler.Login)
+	mux.HandleFunc("/token/refresh", authHandler.Refresh)
+	mux.Handle("/logout", auth(http.HandlerFunc(authHandler.Logout)))
+
+	// Account routes
+	mux.Handle("/me/export",
+		auth(http.HandlerFunc(accountHandler.Export)))
+	mux.Handle("/me/import",
+		auth(http.HandlerFunc(accountHandler.Import)))
+	mux.Handle("/me/delete",
+		auth(http.HandlerFunc(accountHandler.RequestDeletion)))
+	mux.Handle("/me/delete/cancel",
+		auth(http.HandlerFunc(accountHandler.CancelDeletion)))
 
 	// Exercise routes
-	mux.Handle("/exercises",
-		middleware.AuthMiddleware(cfg.JWTSecret)(
-			http.HandlerFunc(exerciseHandler.GetAll)))
-	mux.Handle("/exercises/create",
-		middleware.AuthMiddleware(cfg.JWTSecret)(
-			http.HandlerFunc(exerciseHandler.Create)))
+	mux.Handle("GET /exercises",
+		auth(http.HandlerFunc(exerciseHandler.GetAll)))
+	mux.Handle("POST /exercises",
+		auth(http.HandlerFunc(exerciseHandler.Create)))
+	mux.Handle("GET /exercises/taxonomy",
+		auth(http.HandlerFunc(exerciseHandler.Taxonomy)))
+	mux.Handle("POST /exercises/merge",
+		auth(http.HandlerFunc(exerciseHandler.Merge)))
+	mux.Handle("GET /exercises/{id}",
+		auth(http.HandlerFunc(exerciseHandler.GetByID)))
+	mux.Handle("PUT /exercises/{id}",
+		auth(http.HandlerFunc(exerciseHandler.Update)))
+	mux.Handle("DELETE /exercises/{id}",
+		auth(http.HandlerFunc(exerciseHandler.Delete)))
+	mux.Handle("POST /exercises/{id}/promote",
+		auth(http.HandlerFunc(exerciseHandler.RequestPromotion)))
+
+	// Admin routes
+	mux.Handle("POST /admin/exercises",
+		auth(admin(http.HandlerFunc(exerciseHandler.CreateGlobal))))
+	mux.Handle("GET /admin/exercises/promotions",
+		auth(admin(http.HandlerFunc(exerciseHandler.PendingPromotions))))
+	mux.Handle("POST /admin/exercises/{id}/approve",
+		auth(admin(http.HandlerFunc(exerciseHandler.ApprovePromotion))))
+	mux.Handle("POST /admin/exercises/{id}/reject",
+		auth(admin(http.HandlerFunc(exerciseHandler.RejectPromotion))))
 
 	// Workout routes
-	mux.Handle("/workouts",
-		middleware.AuthMiddleware(cfg.JWTSecret)(
-			http.HandlerFunc(workoutHandler.GetByUser)))
-	mux.Handle("/workouts/create",
-		middleware.AuthMiddleware(cfg.JWTSecret)(
-			http.HandlerFunc(workoutHandler.Create)))
-	mux.Handle("/workouts/update",
-		middleware.AuthMiddleware(cfg.JWTSecret)(
-			http.HandlerFunc(workoutHandler.Update)))
-	mux.Handle("/workouts/delete",
-		middleware.AuthMiddleware(cfg.JWTSecret)(
-			http.HandlerFunc(workoutHandler.Delete)))
-	mux.Handle("/workouts/report", middleware.AuthMiddleware(cfg.JWTSecret)(http.HandlerFunc(workoutHandler.GenerateReport)))
+	mux.Handle("GET /workouts",
+		auth(http.HandlerFunc(workoutHandler.GetByUser)))
+	mux.Handle("POST /workouts",
+		auth(http.HandlerFunc(workoutHandler.Create)))
+	mux.Handle("GET /workouts/report",
+		auth(http.HandlerFunc(workoutHandler.GenerateReport)))
+	mux.Handle("GET /workouts/{id}",
+		auth(http.HandlerFunc(workoutHandler.GetByID)))
+	mux.Handle("PUT /workouts/{id}",
+		auth(http.HandlerFunc(workoutHandler.Update)))
+	mux.Handle("PATCH /workouts/{id}",
+		auth(http.HandlerFunc(workoutHandler.Patch)))
+	mux.Handle("DELETE /workouts/{id}",
+		auth(http.HandlerFunc(workoutHandler.Delete)))
+	mux.Handle("POST /workouts/{id}/exercises",
+		auth(http.HandlerFunc(workoutHandler.AddExercise)))
+	mux.Handle("PATCH /workouts/{id}/exercises/{entry_id}",
+		auth(http.HandlerFunc(workoutHandler.PatchExercise)))
+	mux.Handle("DELETE /workouts/{id}/exercises/{entry_id}",
+		auth(http.HandlerFunc(workoutHandler.RemoveExercise)))
+	mux.Handle("POST /workouts/{id}/start",
+		auth(http.HandlerFunc(workoutHandler.Start)))
+	mux.Handle("POST /workouts/{id}/finish",
+		auth(http.HandlerFunc(workoutHandler.Finish)))
+	mux.Handle("POST /workouts/{id}/skip",
+		auth(http.HandlerFunc(workoutHandler.Skip)))
+	mux.Handle("POST /workouts/{id}/reopen",
+		auth(http.HandlerFunc(workoutHandler.Reopen)))
+
+	// Live session routes
+	mux.Handle("POST /workouts/{id}/session",
+		auth(http.HandlerFunc(liveHandler.Start)))
+	mux.Handle("GET /workouts/{id}/session",
+		auth(http.HandlerFunc(liveHandler.Follow)))
+	mux.Handle("POST /workouts/{id}/session/sets",
+		auth(http.HandlerFunc(liveHandler.CompleteSet)))
+
+	// Deprecated exercise and workout routes, kept until the sunset date
+	// for clients that have not moved to the routes above yet
+	mux.Handle("POST /exercises/create",
+		deprecated("/exercises")(auth(http.HandlerFunc(exerciseHandler.Create))))
+	mux.Handle("PUT /exercises/update",
+		deprecated("/exercises/{id}")(auth(http.HandlerFunc(exerciseHandler.Update))))
+	mux.Handle("DELETE /exercises/delete",
+		deprecated("/exercises/{id}")(auth(http.HandlerFunc(exerciseHandler.Delete))))
+	mux.Handle("POST /admin/exercises/create",
+		deprecated("/admin/exercises")(auth(admin(http.HandlerFunc(exerciseHandler.CreateGlobal)))))
+	mux.Handle("POST /workouts/create",
+		deprecated("/workouts")(auth(http.HandlerFunc(workoutHandler.Create))))
+	mux.Handle("PUT /workouts/update",
+		deprecated("/workouts/{id}")(auth(http.HandlerFunc(workoutHandler.Update))))
+	mux.Handle("DELETE /workouts/delete",
+		deprecated("/workouts/{id}")(auth(http.HandlerFunc(workoutHandler.Delete))))
+	mux.Handle("POST /workouts/start",
+		deprecated("/workouts/{id}/start")(auth(http.HandlerFunc(workoutHandler.Start))))
+	mux.Handle("POST /workouts/finish",
+		deprecated("/workouts/{id}/finish")(auth(http.HandlerFunc(workoutHandler.Finish))))
+	mux.Handle("POST /workouts/skip",
+		deprecated("/workouts/{id}/skip")(auth(http.HandlerFunc(workoutHandler.Skip))))
+	mux.Handle("POST /workouts/reopen",
+		deprecated("/workouts/{id}/reopen")(auth(http.HandlerFunc(workoutHandler.Reopen))))
+
+	// Template routes
+	mux.Handle("/templates",
+		auth(http.HandlerFunc(templateHandler.GetByUser)))
+	mux.Handle("/templates/create",
+		auth(http.HandlerFunc(templateHandler.Create)))
+	mux.Handle("/templates/from-workout",
+		auth(http.HandlerFunc(templateHandler.CreateFromWorkout)))
+	mux.Handle("/templates/update",
+		auth(http.HandlerFunc(templateHandler.Update)))
+	mux.Handle("/templates/delete",
+		auth(http.HandlerFunc(templateHandler.Delete)))
+	mux.Handle("/templates/{id}",
+		auth(http.HandlerFunc(templateHandler.GetByID)))
+	mux.Handle("/templates/{id}/instantiate",
+		auth(http.HandlerFunc(templateHandler.Instantiate)))
+
+	// Program routes
+	mux.Handle("/programs",
+		auth(http.HandlerFunc(programHandler.GetByUser)))
+	mux.Handle("/programs/create",
+		auth(http.HandlerFunc(programHandler.Create)))
+	mux.Handle("/programs/delete",
+		auth(http.HandlerFunc(programHandler.Delete)))
+	mux.Handle("/programs/enrollments",
+		auth(http.HandlerFunc(programHandler.GetEnrollments)))
+	mux.Handle("/programs/{id}",
+		auth(http.HandlerFunc(programHandler.GetByID)))
+	mux.Handle("/programs/{id}/enroll",
+		auth(http.HandlerFunc(programHandler.Enroll)))
+
+	// Schedule routes
+	mux.Handle("GET /schedules",
+		auth(http.HandlerFunc(scheduleHandler.GetByUser)))
+	mux.Handle("POST /schedules",
+		auth(http.HandlerFunc(scheduleHandler.Create)))
+	mux.Handle("GET /schedules/{id}",
+		auth(http.HandlerFunc(scheduleHandler.GetByID)))
+	mux.Handle("PUT /schedules/{id}",
+		auth(http.HandlerFunc(scheduleHandler.Update)))
+	mux.Handle("DELETE /schedules/{id}",
+		auth(http.HandlerFunc(scheduleHandler.Delete)))
+	mux.Handle("POST /schedules/{id}/exceptions",
+		auth(http.HandlerFunc(scheduleHandler.AddException)))
+
+	// Deprecated schedule routes
+	mux.Handle("POST /schedules/create",
+		deprecated("/schedules")(auth(http.HandlerFunc(scheduleHandler.Create))))
+	mux.Handle("PUT /schedules/update",
+		deprecated("/schedules/{id}")(auth(http.HandlerFunc(scheduleHandler.Update))))
+	mux.Handle("DELETE /schedules/delete",
+		deprecated("/schedules/{id}")(auth(http.HandlerFunc(scheduleHandler.Delete))))
+
+	// Personal record routes
+	mux.Handle("/records",
+		auth(http.HandlerFunc(recordHandler.GetByUser)))
+
+	// Analytics routes
+	mux.Handle("/analytics/e1rm",
+		auth(http.HandlerFunc(analyticsHandler.EstimatedOneRepMax)))
+
+	// Calendar routes
+	mux.Handle("/calendar/feed",
+		auth(http.HandlerFunc(calendarHandler.GetFeed)))
+	mux.Handle("/calendar/feed/rotate",
+		auth(http.HandlerFunc(calendarHandler.RotateFeed)))
+	mux.HandleFunc("/calendar/{file}", calendarHandler.Calendar)
+
+	// Import routes
+	mux.Handle("/import",
+		auth(http.HandlerFunc(importHandler.Import)))
 
 	return mux
 }
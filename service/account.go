@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+	"github.com/yeboahd24/workout-tracker/repository"
+	"github.com/yeboahd24/workout-tracker/util"
+)
+
+type AccountService struct {
+	userRepo     *repository.UserRepository
+	sessionRepo  *repository.SessionRepository
+	exerciseRepo *repository.ExerciseRepository
+	workoutRepo  *repository.WorkoutRepository
+	recordRepo   *repository.RecordRepository
+}
+
+func NewAccountService(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, exerciseRepo *repository.ExerciseRepository, workoutRepo *repository.WorkoutRepository, recordRepo *repository.RecordRepository) *AccountService {
+	return &AccountService{userRepo: userRepo, sessionRepo: sessionRepo, exerciseRepo: exerciseRepo, workoutRepo: workoutRepo, recordRepo: recordRepo}
+}
+
+// ArchiveImportResult maps the IDs in an imported archive to the ones they
+// were given in this instance.
+type ArchiveImportResult struct {
+	Workouts         int         `json:"workouts"`
+	CreatedExercises []string    `json:"created_exercises"`
+	ExerciseIDs      map[int]int `json:"exercise_ids"`
+	WorkoutIDs       map[int]int `json:"workout_ids"`
+}
+
+// Export collects the user's profile, every workout with its entries and
+// sets, the user's custom exercises and the catalog exercises their workouts
+// use, deleted ones included, and a monthly report over the whole history.
+func (s *AccountService) Export(ctx context.Context, userID int) (*model.Archive, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	archive := model.NewArchive(user)
+
+	workouts, err := s.workoutRepo.GetByUserID(ctx, userID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	exerciseIDs := make(map[int]bool)
+	for i := len(workouts) - 1; i >= 0; i-- {
+		workout, err := s.workoutRepo.GetByID(ctx, workouts[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		if workout == nil {
+			continue
+		}
+		for _, e := range workout.Exercises {
+			exerciseIDs[e.ExerciseID] = true
+		}
+		archive.Workouts = append(archive.Workouts, workout)
+	}
+
+	exercises, err := s.exerciseRepo.GetAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range exercises {
+		if exerciseIDs[e.ID] || !e.IsGlobal() {
+			archive.Exercises = append(archive.Exercises, e)
+		}
+	}
+
+	// Exercises deleted since are left out of the list but still used by
+	// workouts, and the archive could not be imported without them.
+	for _, id := range archive.MissingExerciseIDs() {
+		exercise, err := s.exerciseRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		archive.Exercises = append(archive.Exercises, exercise)
+	}
+
+	if len(archive.Workouts) > 0 {
+		first := archive.Workouts[0].ScheduledFor
+		last := archive.Workouts[len(archive.Workouts)-1].ScheduledFor
+		report, err := s.workoutRepo.GenerateReport(ctx, userID, model.ReportQuery{
+			Start:   first.Truncate(24 * time.Hour),
+			End:     last.Truncate(24 * time.Hour).Add(24*time.Hour - time.Second),
+			Status:  model.StatusCompleted,
+			GroupBy: model.IntervalMonth,
+		})
+		if err != nil {
+			return nil, err
+		}
+		archive.Reports = append(archive.Reports, report)
+	}
+
+	return archive, nil
+}
+
+// Import restores an archive into the user's account, which must not have
+// any workouts yet. Exercises are matched by name against the ones the user
+// can see and created as custom exercises when missing;
+// workouts, entries and sets get new IDs. The profile and report snapshots
+// are not restored: the account keeps its own profile and reports are
+// recomputed from the workouts. Everything is imported in one transaction,
+// so a failed import leaves the account as it was.
+func (s *AccountService) Import(ctx context.Context, userID int, archive *model.Archive) (*ArchiveImportResult, error) {
+	if err := archive.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.workoutRepo.GetByUserID(ctx, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, model.ErrAccountNotEmpty
+	}
+
+	result := &ArchiveImportResult{
+		CreatedExercises: []string{},
+		ExerciseIDs:      make(map[int]int, len(archive.Exercises)),
+		WorkoutIDs:       make(map[int]int, len(archive.Workouts)),
+	}
+
+	exercises, err := s.exerciseRepo.GetAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]int, len(exercises))
+	for _, e := range exercises {
+		byName[strings.ToLower(strings.TrimSpace(e.Name))] = e.ID
+	}
+
+	tx, err := s.workoutRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, e := range archive.Exercises {
+		key := strings.ToLower(strings.TrimSpace(e.Name))
+		id, ok := byName[key]
+		if !ok {
+			exercise := model.NewCustomExercise(userID, e.Name, e.Description, e.Category)
+			measurementType, err := model.ParseMeasurementType(string(e.MeasurementType))
+			if err != nil {
+				return nil, err
+			}
+			exercise.MeasurementType = measurementType
+			err = exercise.SetTaxonomy(e.PrimaryMuscles, e.SecondaryMuscles, e.Equipment, e.MovementPattern, e.Laterality)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.exerciseRepo.CreateTx(ctx, tx, exercise); err != nil {
+				return nil, err
+			}
+			id = exercise.ID
+			byName[key] = id
+			result.CreatedExercises = append(result.CreatedExercises, e.Name)
+		}
+		result.ExerciseIDs[e.ID] = id
+	}
+
+	// Oldest first, so personal records are detected in the order they
+	// were set.
+	workouts := append([]*model.Workout(nil), archive.Workouts...)
+	sort.SliceStable(workouts, func(i, j int) bool {
+		return workouts[i].ScheduledFor.Before(workouts[j].ScheduledFor)
+	})
+
+	for _, w := range workouts {
+		oldID := w.ID
+		w.ID = 0
+		w.UserID = userID
+		for i := range w.Exercises {
+			entry := &w.Exercises[i]
+			entry.ID = 0
+			entry.WorkoutID = 0
+			entry.ExerciseID = result.ExerciseIDs[entry.ExerciseID]
+			entry.Records = nil
+			for j := range entry.SetLog {
+				entry.SetLog[j].ID = 0
+				entry.SetLog[j].WorkoutExerciseID = 0
+			}
+		}
+
+		if err := s.workoutRepo.CreateTx(ctx, tx, w); err != nil {
+			return nil, err
+		}
+		result.WorkoutIDs[oldID] = w.ID
+
+		if _, err := s.recordRepo.DetectForWorkoutTx(ctx, tx, w); err != nil {
+			return nil, err
+		}
+	}
+	result.Workouts = len(result.WorkoutIDs)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RequestDeletion schedules the account for deletion after the grace period
+// once the password has been confirmed, and logs it out everywhere.
+func (s *AccountService) RequestDeletion(ctx context.Context, userID int, password string) (*model.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.CheckPassword(password) {
+		return nil, util.ErrInvalidCredentials
+	}
+
+	if err := user.ScheduleDeletion(); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetDeletionSchedule(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionRepo.RevokeAllForUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// CancelDeletion keeps an account whose deletion is still pending.
+func (s *AccountService) CancelDeletion(ctx context.Context, userID int) (*model.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.CancelDeletion(); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetDeletionSchedule(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// PurgeDeleted deletes every account whose grace period is over.
+func (s *AccountService) PurgeDeleted(ctx context.Context) error {
+	now := time.Now()
+	ids, err := s.userRepo.GetDueForDeletion(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		purged, err := s.userRepo.Purge(ctx, id, now)
+		if err != nil {
+			log.Printf("Error deleting account %d: %v", id, err)
+			continue
+		}
+		if purged {
+			log.Printf("Deleted account %d", id)
+		}
+	}
+	return nil
+}
+
+// RunPurge deletes accounts that are due every interval until ctx is done.
+func (s *AccountService) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.PurgeDeleted(ctx); err != nil {
+			log.Printf("Error deleting accounts: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
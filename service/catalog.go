@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/catalog"
+	"github.com/yeboahd24/workout-tracker/model"
+	"github.com/yeboahd24/workout-tracker/repository"
+)
+
+type CatalogService struct {
+	exerciseRepo *repository.ExerciseRepository
+}
+
+func NewCatalogService(exerciseRepo *repository.ExerciseRepository) *CatalogService {
+	return &CatalogService{exerciseRepo: exerciseRepo}
+}
+
+// SeedResult lists what seeding the catalog did, by slug.
+type SeedResult struct {
+	Version   int      `json:"version"`
+	DryRun    bool     `json:"dry_run"`
+	Created   []string `json:"created"`
+	Updated   []string `json:"updated"`
+	Unchanged int      `json:"unchanged"`
+	Skipped   []string `json:"skipped"`
+}
+
+// Seed upserts the catalog's exercises into the global catalog by slug.
+// Exercises that already match their entry are left alone, so running it
+// again only applies what changed in the catalog. Exercises an admin has
+// deleted are skipped rather than brought back. With dryRun nothing is
+// written; the result shows what would happen.
+func (s *CatalogService) Seed(ctx context.Context, c *catalog.Catalog, dryRun bool) (*SeedResult, error) {
+	result := &SeedResult{
+		Version: c.Version,
+		DryRun:  dryRun,
+		Created: make([]string, 0),
+		Updated: make([]string, 0),
+		Skipped: make([]string, 0),
+	}
+
+	for _, entry := range c.Exercises {
+		want, err := entry.Exercise()
+		if err != nil {
+			return nil, err
+		}
+
+		existing, err := s.exerciseRepo.GetBySlug(ctx, entry.Slug)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case existing == nil:
+			if !dryRun {
+				if err := s.exerciseRepo.Create(ctx, want); err != nil {
+					return nil, err
+				}
+			}
+			result.Created = append(result.Created, entry.Slug)
+		case existing.DeletedAt != nil:
+			result.Skipped = append(result.Skipped, entry.Slug)
+		case sameCatalogFields(existing, want):
+			result.Unchanged++
+		default:
+			existing.Name = want.Name
+			existing.Description = want.Description
+			existing.Category = want.Category
+			existing.MeasurementType = want.MeasurementType
+			existing.PrimaryMuscles = want.PrimaryMuscles
+			existing.SecondaryMuscles = want.SecondaryMuscles
+			existing.Equipment = want.Equipment
+			existing.MovementPattern = want.MovementPattern
+			existing.Laterality = want.Laterality
+			existing.UpdatedAt = time.Now()
+			if !dryRun {
+				if err := s.exerciseRepo.Update(ctx, existing); err != nil {
+					return nil, err
+				}
+			}
+			result.Updated = append(result.Updated, entry.Slug)
+		}
+	}
+
+	return result, nil
+}
+
+// sameCatalogFields reports whether the stored exercise already has every
+// field the catalog sets. Muscle groups and equipment are compared as sets
+// since the repository returns them sorted.
+func sameCatalogFields(a, b *model.Exercise) bool {
+	return a.Name == b.Name &&
+		a.Description == b.Description &&
+		a.Category == b.Category &&
+		a.MeasurementType == b.MeasurementType &&
+		a.MovementPattern == b.MovementPattern &&
+		a.Laterality == b.Laterality &&
+		sameSlugs(a.PrimaryMuscles, b.PrimaryMuscles) &&
+		sameSlugs(a.SecondaryMuscles, b.SecondaryMuscles) &&
+		sameSlugs(a.Equipment, b.Equipment)
+}
+
+func sameSlugs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
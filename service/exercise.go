@@ -15,8 +15,8 @@ func NewExerciseService(exerciseRepo *repository.ExerciseRepository) *ExerciseSe
 	return &ExerciseService{exerciseRepo: exerciseRepo}
 }
 
-func (s *ExerciseService) CreateExercise(ctx context.Context, name, description, category string) (*model.Exercise, error) {
-	exercise := model.NewExercise(name, description, category)
+func (s *ExerciseService) CreateExercise(ctx context.Context, userID int, name, description, category string) (*model.Exercise, error) {
+	exercise := model.NewCustomExercise(userID, name, description, category)
 	err := s.exerciseRepo.Create(ctx, exercise)
 	if err != nil {
 		return nil, err
@@ -28,6 +28,6 @@ func (s *ExerciseService) GetExerciseByID(ctx context.Context, id int) (*model.E
 	return s.exerciseRepo.GetByID(ctx, id)
 }
 
-func (s *ExerciseService) GetAllExercises(ctx context.Context) ([]*model.Exercise, error) {
-	return s.exerciseRepo.GetAll(ctx)
+func (s *ExerciseService) GetAllExercises(ctx context.Context, userID int) ([]*model.Exercise, error) {
+	return s.exerciseRepo.GetAll(ctx, userID)
 }
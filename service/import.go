@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/importer"
+	"github.com/yeboahd24/workout-tracker/model"
+	"github.com/yeboahd24/workout-tracker/repository"
+)
+
+// importCategory is given to exercises created because an export named one
+// we did not know.
+const importCategory = "Imported"
+
+type ImportService struct {
+	exerciseRepo *repository.ExerciseRepository
+	workoutRepo  *repository.WorkoutRepository
+	recordRepo   *repository.RecordRepository
+}
+
+func NewImportService(exerciseRepo *repository.ExerciseRepository, workoutRepo *repository.WorkoutRepository, recordRepo *repository.RecordRepository) *ImportService {
+	return &ImportService{exerciseRepo: exerciseRepo, workoutRepo: workoutRepo, recordRepo: recordRepo}
+}
+
+type ImportOptions struct {
+	Format   importer.Format
+	Location *time.Location
+	DryRun   bool
+}
+
+type ImportResult struct {
+	DryRun           bool                `json:"dry_run"`
+	Rows             int                 `json:"rows"`
+	WorkoutsImported int                 `json:"workouts_imported"`
+	Workouts         []ImportedWorkout   `json:"workouts"`
+	Duplicates       []ImportedWorkout   `json:"duplicates"`
+	CreatedExercises []string            `json:"created_exercises"`
+	Errors           []importer.RowError `json:"errors"`
+}
+
+// ImportedWorkout previews a workout found in the file.
+type ImportedWorkout struct {
+	ID           int       `json:"id,omitempty"`
+	Name         string    `json:"name"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+	Exercises    int       `json:"exercises"`
+	Sets         int       `json:"sets"`
+}
+
+// Import reads an export and stores its workouts for the user as completed
+// workouts, oldest first so personal records are detected in order.
+// Workouts the user already has with the same name and time are skipped.
+// With DryRun nothing is written; the result shows what would happen.
+func (s *ImportService) Import(ctx context.Context, userID int, r io.Reader, opts ImportOptions) (*ImportResult, error) {
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+
+	rows, rowErrors, err := importer.Parse(r, opts.Format, opts.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		DryRun:           opts.DryRun,
+		Rows:             len(rows) + len(rowErrors),
+		Workouts:         []ImportedWorkout{},
+		Duplicates:       []ImportedWorkout{},
+		CreatedExercises: []string{},
+		Errors:           rowErrors,
+	}
+	if result.Errors == nil {
+		result.Errors = []importer.RowError{}
+	}
+
+	exercises, err := s.exerciseRepo.GetAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	exerciseIDs := make(map[string]int, len(exercises))
+	for _, e := range exercises {
+		exerciseIDs[strings.ToLower(strings.TrimSpace(e.Name))] = e.ID
+	}
+
+	for _, imported := range importer.Group(rows) {
+		preview := ImportedWorkout{
+			Name:         imported.Name,
+			ScheduledFor: imported.StartedAt,
+			Exercises:    len(imported.Exercises),
+		}
+		for _, e := range imported.Exercises {
+			preview.Sets += len(e.Sets)
+		}
+
+		exists, err := s.workoutRepo.Exists(ctx, userID, imported.Name, imported.StartedAt)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			result.Duplicates = append(result.Duplicates, preview)
+			continue
+		}
+
+		workout := model.NewWorkout(userID, imported.Name, imported.Notes, imported.StartedAt)
+		workout.Status = model.StatusCompleted
+		startedAt := imported.StartedAt
+		completedAt := startedAt
+		if imported.EndedAt != nil {
+			completedAt = *imported.EndedAt
+		}
+		workout.StartedAt = &startedAt
+		workout.CompletedAt = &completedAt
+
+		for _, e := range imported.Exercises {
+			key := strings.ToLower(e.Name)
+			exerciseID, ok := exerciseIDs[key]
+			if !ok {
+				result.CreatedExercises = append(result.CreatedExercises, e.Name)
+				if !opts.DryRun {
+					exercise := model.NewCustomExercise(userID, e.Name, "", importCategory)
+					if err := s.exerciseRepo.Create(ctx, exercise); err != nil {
+						return nil, err
+					}
+					exerciseID = exercise.ID
+				}
+				exerciseIDs[key] = exerciseID
+			}
+
+			entry := workout.AddExercise(exerciseID, 0, 0, 0, e.Notes)
+			if err := entry.SetSetLog(e.Sets); err != nil {
+				for _, line := range e.Lines {
+					result.Errors = append(result.Errors, importer.RowError{Line: line, Message: err.Error()})
+				}
+				workout.Exercises = workout.Exercises[:len(workout.Exercises)-1]
+			}
+		}
+
+		if !opts.DryRun {
+			if err := s.workoutRepo.Create(ctx, workout); err != nil {
+				return nil, err
+			}
+			if _, err := s.recordRepo.DetectForWorkout(ctx, workout); err != nil {
+				return nil, err
+			}
+			preview.ID = workout.ID
+			result.WorkoutsImported++
+		}
+		result.Workouts = append(result.Workouts, preview)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+	"github.com/yeboahd24/workout-tracker/repository"
+)
+
+// liveBuffer is how many events a slow device may fall behind before it
+// misses some.
+const liveBuffer = 32
+
+// LiveService runs live workout sessions. Each account has a hub in memory
+// that fans the events of its sessions out to every device following them
+// and runs the rest timer between sets. Sets are stored as they are logged,
+// so a restart only loses the running timers and the open streams.
+type LiveService struct {
+	workoutRepo *repository.WorkoutRepository
+
+	mu   sync.Mutex
+	hubs map[int]*liveHub
+}
+
+// liveHub holds an account's followers, by the workout they follow, and the
+// rest timer running for each of its workouts.
+type liveHub struct {
+	followers map[chan model.LiveEvent]int
+	timers    map[int]*restTimer
+}
+
+type restTimer struct {
+	rest model.RestTimer
+	next *model.NextSet
+	stop chan struct{}
+}
+
+func NewLiveService(workoutRepo *repository.WorkoutRepository) *LiveService {
+	return &LiveService{workoutRepo: workoutRepo, hubs: make(map[int]*liveHub)}
+}
+
+// Start puts a scheduled workout in progress. A workout that is already in
+// progress is left as it is, so another device can pick the session up.
+func (s *LiveService) Start(ctx context.Context, workout *model.Workout) error {
+	if workout.Status == model.StatusInProgress {
+		return nil
+	}
+	if err := workout.Start(); err != nil {
+		return err
+	}
+	if err := s.workoutRepo.UpdateStatus(ctx, workout); err != nil {
+		return err
+	}
+	s.Changed(workout)
+	return nil
+}
+
+// Follow subscribes to the events of a workout in progress. The first event
+// is a snapshot of the workout with the rest timer, if one is running. The
+// channel is closed when the session ends; call the returned function to
+// stop following earlier.
+func (s *LiveService) Follow(workout *model.Workout) (<-chan model.LiveEvent, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hub := s.hub(workout.UserID)
+	events := make(chan model.LiveEvent, liveBuffer)
+	hub.followers[events] = workout.ID
+	events <- s.snapshot(hub, workout)
+
+	return events, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := hub.followers[events]; ok {
+			delete(hub.followers, events)
+			close(events)
+		}
+		s.release(workout.UserID, hub)
+	}
+}
+
+// CompleteSet tells every follower about a set the workout has just logged
+// and stored. The rest timer starts over, with restSeconds if given and the
+// rest the workout prescribes otherwise; without rest the next set is
+// announced straight away.
+func (s *LiveService) CompleteSet(workout *model.Workout, set *model.WorkoutSet, records []model.RecordType, restSeconds *int) *model.LiveEvent {
+	next := workout.NextSet()
+	rest := workout.RestAfter(set.WorkoutExerciseID, next)
+	if restSeconds != nil && next != nil {
+		rest = *restSeconds
+	}
+
+	now := time.Now()
+	event := model.LiveEvent{
+		Type:      model.LiveSetCompleted,
+		WorkoutID: workout.ID,
+		At:        now,
+		Set:       set,
+		Records:   records,
+		Next:      next,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hub := s.hub(workout.UserID)
+	s.stopTimer(hub, workout.ID)
+	if rest > 0 {
+		timer := &restTimer{
+			rest: model.RestTimer{Seconds: rest, Remaining: rest, EndsAt: now.Add(time.Duration(rest) * time.Second)},
+			next: next,
+			stop: make(chan struct{}),
+		}
+		hub.timers[workout.ID] = timer
+		countdown := timer.rest
+		event.Rest = &countdown
+		go s.runTimer(workout.UserID, workout.ID, timer)
+	}
+	s.publish(hub, event)
+	if rest <= 0 && next != nil {
+		s.publish(hub, model.LiveEvent{Type: model.LiveNextSet, WorkoutID: workout.ID, At: now, Next: next})
+	}
+	s.release(workout.UserID, hub)
+
+	return &event
+}
+
+// Changed tells the followers of a workout that it was changed outside the
+// session. A workout that is no longer in progress ends its session.
+func (s *LiveService) Changed(workout *model.Workout) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hub, ok := s.hubs[workout.UserID]
+	if !ok {
+		return
+	}
+	if workout.Status == model.StatusInProgress {
+		s.publish(hub, s.snapshot(hub, workout))
+		return
+	}
+	s.end(hub, model.LiveEvent{Type: model.LiveEnded, WorkoutID: workout.ID, At: time.Now(), Workout: workout})
+	s.release(workout.UserID, hub)
+}
+
+// Deleted ends the session of a workout that no longer exists.
+func (s *LiveService) Deleted(userID, workoutID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hub, ok := s.hubs[userID]
+	if !ok {
+		return
+	}
+	s.end(hub, model.LiveEvent{Type: model.LiveEnded, WorkoutID: workoutID, At: time.Now()})
+	s.release(userID, hub)
+}
+
+// runTimer ticks every second until the rest is over or the timer is
+// replaced, then announces the next set.
+func (s *LiveService) runTimer(userID, workoutID int, timer *restTimer) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timer.stop:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			hub, ok := s.hubs[userID]
+			if !ok || hub.timers[workoutID] != timer {
+				s.mu.Unlock()
+				return
+			}
+
+			timer.rest.Remaining = remaining(timer.rest.EndsAt, now)
+			rest := timer.rest
+			if rest.Remaining > 0 {
+				s.publish(hub, model.LiveEvent{Type: model.LiveRestTick, WorkoutID: workoutID, At: now, Rest: &rest})
+				s.mu.Unlock()
+				continue
+			}
+
+			delete(hub.timers, workoutID)
+			s.publish(hub, model.LiveEvent{Type: model.LiveRestFinished, WorkoutID: workoutID, At: now, Rest: &rest})
+			if timer.next != nil {
+				s.publish(hub, model.LiveEvent{Type: model.LiveNextSet, WorkoutID: workoutID, At: now, Next: timer.next})
+			}
+			s.release(userID, hub)
+			s.mu.Unlock()
+			return
+		}
+	}
+}
+
+// The helpers below expect s.mu to be held.
+
+func (s *LiveService) hub(userID int) *liveHub {
+	hub, ok := s.hubs[userID]
+	if !ok {
+		hub = &liveHub{
+			followers: make(map[chan model.LiveEvent]int),
+			timers:    make(map[int]*restTimer),
+		}
+		s.hubs[userID] = hub
+	}
+	return hub
+}
+
+// release forgets a hub that has nothing left to do.
+func (s *LiveService) release(userID int, hub *liveHub) {
+	if len(hub.followers) == 0 && len(hub.timers) == 0 && s.hubs[userID] == hub {
+		delete(s.hubs, userID)
+	}
+}
+
+func (s *LiveService) snapshot(hub *liveHub, workout *model.Workout) model.LiveEvent {
+	now := time.Now()
+	event := model.LiveEvent{
+		Type:      model.LiveSnapshot,
+		WorkoutID: workout.ID,
+		At:        now,
+		Workout:   workout,
+		Next:      workout.NextSet(),
+	}
+	if timer, ok := hub.timers[workout.ID]; ok {
+		rest := timer.rest
+		rest.Remaining = remaining(rest.EndsAt, now)
+		event.Rest = &rest
+	}
+	return event
+}
+
+// publish sends an event to the followers of its workout. A follower whose
+// buffer is full misses it rather than holding up the others.
+func (s *LiveService) publish(hub *liveHub, event model.LiveEvent) {
+	for events, workoutID := range hub.followers {
+		if workoutID != event.WorkoutID {
+			continue
+		}
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// end sends the last event of a session and closes its streams.
+func (s *LiveService) end(hub *liveHub, event model.LiveEvent) {
+	s.stopTimer(hub, event.WorkoutID)
+	s.publish(hub, event)
+	for events, workoutID := range hub.followers {
+		if workoutID == event.WorkoutID {
+			delete(hub.followers, events)
+			close(events)
+		}
+	}
+}
+
+func (s *LiveService) stopTimer(hub *liveHub, workoutID int) {
+	if timer, ok := hub.timers[workoutID]; ok {
+		close(timer.stop)
+		delete(hub.timers, workoutID)
+	}
+}
+
+func remaining(endsAt, now time.Time) int {
+	return int(math.Ceil(endsAt.Sub(now).Seconds()))
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+	"github.com/yeboahd24/workout-tracker/repository"
+)
+
+type ProgramService struct {
+	programRepo *repository.ProgramRepository
+	workoutRepo *repository.WorkoutRepository
+}
+
+func NewProgramService(programRepo *repository.ProgramRepository, workoutRepo *repository.WorkoutRepository) *ProgramService {
+	return &ProgramService{programRepo: programRepo, workoutRepo: workoutRepo}
+}
+
+// Enroll schedules a workout for every day of the program starting on start
+// and sets their initial targets. If any step fails, the enrollment and its
+// workouts are removed again.
+func (s *ProgramService) Enroll(ctx context.Context, program *model.Program, userID int, start time.Time) (*model.ProgramEnrollment, error) {
+	enrollment := model.NewProgramEnrollment(program.ID, userID, start)
+
+	for _, day := range program.Days {
+		name := day.Name
+		if name == "" {
+			name = fmt.Sprintf("%s - Week %d Day %d", program.Name, day.Week, day.Day)
+		}
+
+		workout := model.NewWorkout(userID, name, program.Description, day.ScheduledFor(start))
+		for _, e := range day.Exercises {
+			workout.AddExercise(e.ExerciseID, e.Sets, e.Reps, e.Progression.StartWeight, "")
+		}
+
+		if err := s.workoutRepo.Create(ctx, workout); err != nil {
+			s.discard(ctx, enrollment)
+			return nil, err
+		}
+
+		enrollment.Workouts = append(enrollment.Workouts, model.EnrollmentWorkout{
+			WorkoutID:    workout.ID,
+			ProgramDayID: day.ID,
+			Week:         day.Week,
+			Day:          day.Day,
+		})
+	}
+
+	if err := s.programRepo.CreateEnrollment(ctx, enrollment); err != nil {
+		s.discard(ctx, enrollment)
+		return nil, err
+	}
+
+	if err := s.applyTargets(ctx, program, enrollment); err != nil {
+		if err := s.programRepo.DeleteEnrollment(ctx, enrollment.ID); err != nil {
+			log.Printf("Error deleting enrollment %d: %v", enrollment.ID, err)
+		}
+		s.discard(ctx, enrollment)
+		return nil, err
+	}
+
+	return enrollment, nil
+}
+
+// SyncTargets recomputes the targets of the remaining sessions of the
+// program the workout belongs to. It is a no-op for workouts that were not
+// scheduled by a program.
+func (s *ProgramService) SyncTargets(ctx context.Context, workoutID int) error {
+	enrollment, err := s.programRepo.GetEnrollmentByWorkoutID(ctx, workoutID)
+	if err != nil || enrollment == nil {
+		return err
+	}
+
+	program, err := s.programRepo.GetByID(ctx, enrollment.ProgramID)
+	if err != nil || program == nil {
+		return err
+	}
+
+	return s.applyTargets(ctx, program, enrollment)
+}
+
+// progressionTrack identifies the sessions whose results feed into each
+// other: the same exercise progressed under the same rule.
+type progressionTrack struct {
+	exerciseID int
+	rule       model.ProgressionRule
+}
+
+// applyTargets replays the enrollment in calendar order. Completed sessions
+// contribute what was actually logged; sessions still to come get a fresh
+// target and are assumed to succeed when planning the ones after them.
+func (s *ProgramService) applyTargets(ctx context.Context, program *model.Program, enrollment *model.ProgramEnrollment) error {
+	days := make(map[int]*model.ProgramDay, len(program.Days))
+	for i := range program.Days {
+		days[program.Days[i].ID] = &program.Days[i]
+	}
+
+	history := make(map[progressionTrack][]model.SessionResult)
+	for _, ew := range enrollment.Workouts {
+		day, ok := days[ew.ProgramDayID]
+		if !ok {
+			continue
+		}
+
+		workout, err := s.workoutRepo.GetByID(ctx, ew.WorkoutID)
+		if err != nil {
+			return err
+		}
+		if workout == nil {
+			continue
+		}
+
+		// Entries were created in program order, so the n-th occurrence of an
+		// exercise in the day maps to the n-th entry for it by ID.
+		sort.Slice(workout.Exercises, func(i, j int) bool {
+			return workout.Exercises[i].ID < workout.Exercises[j].ID
+		})
+		seen := make(map[int]int)
+
+		for _, pe := range day.Exercises {
+			track := progressionTrack{exerciseID: pe.ExerciseID, rule: pe.Progression.Rule}
+			target := pe.Progression.NextTarget(pe.Sets, pe.Reps, ew.Week, history[track])
+
+			entry := nthEntry(workout, pe.ExerciseID, seen[pe.ExerciseID])
+			seen[pe.ExerciseID]++
+
+			switch workout.Status {
+			case model.StatusCompleted:
+				if entry != nil {
+					history[track] = append(history[track], model.ResultFromEntry(entry, ew.Week, target))
+				}
+			case model.StatusScheduled:
+				if entry != nil && (entry.Sets != target.Sets || entry.Reps != target.Reps || entry.Weight != target.Weight) {
+					err := s.workoutRepo.UpdateExerciseTarget(ctx, entry.ID, target.Sets, target.Reps, target.Weight)
+					if err != nil {
+						return err
+					}
+				}
+				history[track] = append(history[track], target.ProjectedResult(ew.Week))
+			case model.StatusInProgress:
+				history[track] = append(history[track], target.ProjectedResult(ew.Week))
+			}
+		}
+	}
+
+	return nil
+}
+
+// discard removes the workouts created for an enrollment that could not be
+// completed.
+func (s *ProgramService) discard(ctx context.Context, enrollment *model.ProgramEnrollment) {
+	for _, ew := range enrollment.Workouts {
+		if err := s.workoutRepo.Delete(ctx, ew.WorkoutID); err != nil {
+			log.Printf("Error deleting workout %d: %v", ew.WorkoutID, err)
+		}
+	}
+}
+
+func nthEntry(workout *model.Workout, exerciseID, n int) *model.WorkoutExercise {
+	for i := range workout.Exercises {
+		if workout.Exercises[i].ExerciseID != exerciseID {
+			continue
+		}
+		if n == 0 {
+			return &workout.Exercises[i]
+		}
+		n--
+	}
+	return nil
+}
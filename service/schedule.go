@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/yeboahd24/workout-tracker/model"
+	"github.com/yeboahd24/workout-tracker/repository"
+)
+
+var ErrNoOccurrence = errors.New("the schedule has no occurrence at that time")
+
+type ScheduleService struct {
+	scheduleRepo *repository.ScheduleRepository
+	workoutRepo  *repository.WorkoutRepository
+	templateRepo *repository.TemplateRepository
+}
+
+func NewScheduleService(scheduleRepo *repository.ScheduleRepository, workoutRepo *repository.WorkoutRepository, templateRepo *repository.TemplateRepository) *ScheduleService {
+	return &ScheduleService{scheduleRepo: scheduleRepo, workoutRepo: workoutRepo, templateRepo: templateRepo}
+}
+
+// Create stores the schedule and generates its workouts for the coming
+// horizon. Occurrences that are already in the past are not generated.
+func (s *ScheduleService) Create(ctx context.Context, schedule *model.Schedule) error {
+	tx, err := s.scheduleRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	schedule.GeneratedUntil = time.Now()
+	if err := s.scheduleRepo.CreateTx(ctx, tx, schedule); err != nil {
+		return err
+	}
+	if err := s.generate(ctx, tx, schedule, time.Now().Add(model.ScheduleHorizon)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Extend generates workouts for every schedule whose horizon has moved on
+// since it was last extended.
+func (s *ScheduleService) Extend(ctx context.Context) error {
+	until := time.Now().Add(model.ScheduleHorizon)
+	due, err := s.scheduleRepo.GetDue(ctx, until)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range due {
+		schedule, err := s.scheduleRepo.GetByID(ctx, d.ID)
+		if err != nil {
+			log.Printf("Error fetching schedule %d: %v", d.ID, err)
+			continue
+		}
+		if schedule == nil {
+			continue
+		}
+		if err := s.extend(ctx, schedule, until); err != nil {
+			log.Printf("Error extending schedule %d: %v", schedule.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *ScheduleService) extend(ctx context.Context, schedule *model.Schedule, until time.Time) error {
+	tx, err := s.scheduleRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.generate(ctx, tx, schedule, until); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Run extends schedules every interval until ctx is done.
+func (s *ScheduleService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Extend(ctx); err != nil {
+			log.Printf("Error extending schedules: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EditOccurrence applies a change to the workout for one occurrence only,
+// generating it first if it is beyond the horizon. The workout is detached
+// from the series so later edits to the series leave it alone.
+func (s *ScheduleService) EditOccurrence(ctx context.Context, schedule *model.Schedule, occurrence time.Time, apply func(*model.Workout)) (*model.Workout, error) {
+	workoutID := 0
+	for _, sw := range schedule.Workouts {
+		if sw.Occurrence.Equal(occurrence) {
+			workoutID = sw.WorkoutID
+		}
+	}
+
+	tx, err := s.scheduleRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var workout *model.Workout
+	if workoutID == 0 {
+		occurrences, err := schedule.Occurrences(occurrence.Add(-time.Second), occurrence)
+		if err != nil {
+			return nil, err
+		}
+		if len(occurrences) == 0 {
+			return nil, ErrNoOccurrence
+		}
+
+		workout, err = s.createWorkout(ctx, tx, schedule, occurrence)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		workout, err = s.workoutRepo.GetByID(ctx, workoutID)
+		if err != nil {
+			return nil, err
+		}
+		if workout == nil {
+			return nil, ErrNoOccurrence
+		}
+	}
+
+	apply(workout)
+	workout.UpdatedAt = time.Now()
+	if err := s.workoutRepo.UpdateTx(ctx, tx, workout); err != nil {
+		return nil, err
+	}
+	if err := s.scheduleRepo.DetachTx(ctx, tx, workout.ID); err != nil {
+		return nil, err
+	}
+
+	return workout, tx.Commit()
+}
+
+// Reschedule saves changes made to the whole series and replaces the
+// workouts generated for occurrences from now on that have not been
+// started or edited on their own. Either all of it happens or none does.
+func (s *ScheduleService) Reschedule(ctx context.Context, schedule *model.Schedule) error {
+	tx, err := s.scheduleRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if err := s.removeFrom(ctx, tx, schedule, now); err != nil {
+		return err
+	}
+
+	schedule.GeneratedUntil = now
+	schedule.UpdatedAt = now
+	if err := s.generate(ctx, tx, schedule, now.Add(model.ScheduleHorizon)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SplitAt saves previous, which Split has ended just before occurrence, and
+// creates next to continue the series with its changes applied, in one
+// transaction.
+func (s *ScheduleService) SplitAt(ctx context.Context, previous, next *model.Schedule, occurrence time.Time) error {
+	tx, err := s.scheduleRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.removeFrom(ctx, tx, previous, occurrence); err != nil {
+		return err
+	}
+	if err := s.scheduleRepo.UpdateTx(ctx, tx, previous); err != nil {
+		return err
+	}
+
+	next.GeneratedUntil = occurrence.Add(-time.Second)
+	if now := time.Now(); next.GeneratedUntil.Before(now) {
+		next.GeneratedUntil = now
+	}
+	if err := s.scheduleRepo.CreateTx(ctx, tx, next); err != nil {
+		return err
+	}
+	if err := s.generate(ctx, tx, next, time.Now().Add(model.ScheduleHorizon)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddException leaves a date out of the series and removes the workout
+// generated for it, unless it was started or edited on its own.
+func (s *ScheduleService) AddException(ctx context.Context, schedule *model.Schedule, date string) error {
+	if err := schedule.AddException(date); err != nil {
+		return err
+	}
+
+	tx, err := s.scheduleRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	kept := schedule.Workouts[:0]
+	for _, sw := range schedule.Workouts {
+		if !sw.Detached && schedule.IsException(sw.Occurrence) {
+			removed, err := s.removeWorkout(ctx, tx, sw.WorkoutID)
+			if err != nil {
+				return err
+			}
+			if removed {
+				continue
+			}
+		}
+		kept = append(kept, sw)
+	}
+	schedule.Workouts = kept
+
+	if err := s.scheduleRepo.UpdateTx(ctx, tx, schedule); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes the schedule and its upcoming workouts. Workouts that have
+// already happened are kept.
+func (s *ScheduleService) Delete(ctx context.Context, schedule *model.Schedule) error {
+	tx, err := s.scheduleRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.removeFrom(ctx, tx, schedule, time.Now()); err != nil {
+		return err
+	}
+	if err := s.scheduleRepo.DeleteTx(ctx, tx, schedule.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// generate creates workouts for the occurrences between the schedule's
+// GeneratedUntil and until that do not have one yet.
+func (s *ScheduleService) generate(ctx context.Context, tx *sql.Tx, schedule *model.Schedule, until time.Time) error {
+	occurrences, err := schedule.Occurrences(schedule.GeneratedUntil, until)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[int64]bool, len(schedule.Workouts))
+	for _, sw := range schedule.Workouts {
+		existing[sw.Occurrence.Unix()] = true
+	}
+
+	for _, occurrence := range occurrences {
+		if existing[occurrence.Unix()] {
+			continue
+		}
+		if _, err := s.createWorkout(ctx, tx, schedule, occurrence); err != nil {
+			return err
+		}
+	}
+
+	schedule.GeneratedUntil = until
+	return s.scheduleRepo.UpdateTx(ctx, tx, schedule)
+}
+
+func (s *ScheduleService) createWorkout(ctx context.Context, tx *sql.Tx, schedule *model.Schedule, occurrence time.Time) (*model.Workout, error) {
+	var template *model.WorkoutTemplate
+	if schedule.TemplateID != nil {
+		t, err := s.templateRepo.GetByID(ctx, *schedule.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		template = t
+	}
+
+	var workout *model.Workout
+	if template != nil {
+		workout = template.Instantiate(occurrence)
+		workout.UserID = schedule.UserID
+		workout.Name = schedule.Name
+		if schedule.Description != "" {
+			workout.Description = schedule.Description
+		}
+	} else {
+		workout = model.NewWorkout(schedule.UserID, schedule.Name, schedule.Description, occurrence)
+	}
+
+	if err := s.workoutRepo.CreateTx(ctx, tx, workout); err != nil {
+		return nil, err
+	}
+	if err := s.scheduleRepo.AddWorkoutTx(ctx, tx, schedule.ID, workout.ID, occurrence); err != nil {
+		return nil, err
+	}
+	schedule.Workouts = append(schedule.Workouts, model.ScheduledWorkout{WorkoutID: workout.ID, Occurrence: occurrence})
+
+	return workout, nil
+}
+
+// removeFrom deletes the workouts generated for occurrences at or after
+// from that are still scheduled and have not been edited on their own.
+func (s *ScheduleService) removeFrom(ctx context.Context, tx *sql.Tx, schedule *model.Schedule, from time.Time) error {
+	kept := schedule.Workouts[:0]
+	for _, sw := range schedule.Workouts {
+		if !sw.Detached && !sw.Occurrence.Before(from) {
+			removed, err := s.removeWorkout(ctx, tx, sw.WorkoutID)
+			if err != nil {
+				return err
+			}
+			if removed {
+				continue
+			}
+		}
+		kept = append(kept, sw)
+	}
+	schedule.Workouts = kept
+	return nil
+}
+
+// removeWorkout deletes a generated workout if it has not been started.
+func (s *ScheduleService) removeWorkout(ctx context.Context, tx *sql.Tx, workoutID int) (bool, error) {
+	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+	if err != nil {
+		return false, err
+	}
+	if workout == nil {
+		return true, nil
+	}
+	if workout.Status != model.StatusScheduled {
+		return false, nil
+	}
+	return true, s.workoutRepo.DeleteTx(ctx, tx, workoutID)
+}
@@ -30,8 +30,8 @@ func (s *WorkoutService) GetWorkoutByID(ctx context.Context, id int) (*model.Wor
 	return s.workoutRepo.GetByID(ctx, id)
 }
 
-func (s *WorkoutService) GetWorkoutsByUserID(ctx context.Context, userID int) ([]*model.Workout, error) {
-	return s.workoutRepo.GetByUserID(ctx, userID)
+func (s *WorkoutService) GetWorkoutsByUserID(ctx context.Context, userID int, status model.WorkoutStatus) ([]*model.Workout, error) {
+	return s.workoutRepo.GetByUserID(ctx, userID, status)
 }
 
 func (s *WorkoutService) UpdateWorkout(ctx context.Context, workout *model.Workout) error {
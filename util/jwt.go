@@ -13,16 +13,20 @@ var (
 	ErrUnauthorized       = errors.New("unauthorized")
 )
 
-func GenerateJWT(userID int, secret string) (string, error) {
+const AccessTokenTTL = 15 * time.Minute
+
+func GenerateJWT(userID int, sessionID, secret string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+		"sid":     sessionID,
+		"exp":     time.Now().Add(AccessTokenTTL).Unix(),
 	})
 
 	return token.SignedString([]byte(secret))
 }
 
-func ValidateJWT(tokenString, secret string) (int, error) {
+// ValidateJWT returns the user and session IDs carried by a valid access token.
+func ValidateJWT(tokenString, secret string) (int, string, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
@@ -31,15 +35,22 @@ func ValidateJWT(tokenString, secret string) (int, error) {
 	})
 
 	if err != nil {
-		return 0, err
+		return 0, "", err
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID := int(claims["user_id"].(float64))
-		return userID, nil
+		userID, ok := claims["user_id"].(float64)
+		if !ok {
+			return 0, "", errors.New("invalid token")
+		}
+		sessionID, ok := claims["sid"].(string)
+		if !ok {
+			return 0, "", errors.New("invalid token")
+		}
+		return int(userID), sessionID, nil
 	}
 
-	return 0, errors.New("invalid token")
+	return 0, "", errors.New("invalid token")
 }
 
 func GetUserIDFromContext(ctx context.Context) (int, error) {
@@ -49,3 +60,11 @@ func GetUserIDFromContext(ctx context.Context) (int, error) {
 	}
 	return userID, nil
 }
+
+func GetSessionIDFromContext(ctx context.Context) (string, error) {
+	sessionID, ok := ctx.Value("sessionID").(string)
+	if !ok {
+		return "", ErrUnauthorized
+	}
+	return sessionID, nil
+}